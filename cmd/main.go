@@ -1,76 +1,179 @@
 package main
 
 import (
-	"encoding/csv"
+	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"real-time-price-aggregator/internal/api"
 	"real-time-price-aggregator/internal/cache"
+	"real-time-price-aggregator/internal/cluster"
+	"real-time-price-aggregator/internal/events"
 	"real-time-price-aggregator/internal/fetcher"
+	"real-time-price-aggregator/internal/leader"
 	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/pricepb"
+	"real-time-price-aggregator/internal/redisclient"
 	"real-time-price-aggregator/internal/refresher"
 	"real-time-price-aggregator/internal/storage"
+	"real-time-price-aggregator/internal/symbols"
+	"real-time-price-aggregator/internal/tiers"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
-// supportedAssets holds the list of supported asset symbols
-var supportedAssets map[string]bool
+// durationFromEnv reads a duration such as "30s" from an environment variable
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %v: %v", name, v, fallback, err)
+		return fallback
+	}
+	return d
+}
 
-// loadSymbols loads supported asset symbols from a CSV file
-func loadSymbols(filename string) []string {
-	// Initialize the map to store supported assets
-	supportedAssets = make(map[string]bool)
-	supportedList := []string{}
+// intFromEnv reads an integer from an environment variable
+func intFromEnv(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d: %v", name, v, fallback, err)
+		return fallback
+	}
+	return n
+}
 
-	// Open the CSV file
-	file, err := os.Open(filename)
+// floatFromEnv reads a number such as "1.5" from an environment variable
+func floatFromEnv(name string, fallback float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
 	if err != nil {
-		log.Fatalf("Failed to open symbols file: %v", err)
+		log.Printf("Invalid %s %q, using default %g: %v", name, v, fallback, err)
+		return fallback
 	}
-	defer file.Close()
+	return f
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// boolFromEnv reads a boolean such as "true" or "0" from an environment variable
+func boolFromEnv(name string, fallback bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
 	if err != nil {
-		log.Fatalf("Failed to read symbols file: %v", err)
+		log.Printf("Invalid %s %q, using default %t: %v", name, v, fallback, err)
+		return fallback
 	}
+	return b
+}
+
+// reportGRPCHealth sets the gRPC health status of the server and of the
+// price service from ready every interval until stop is closed
+func reportGRPCHealth(healthServer *health.Server, ready func() bool, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		healthServer.SetServingStatus("", status)
+		healthServer.SetServingStatus(pricepb.PriceService_ServiceDesc.ServiceName, status)
 
-	for _, record := range records[1:] { // Skip header
-		asset := strings.ToLower(record[0])
-		supportedAssets[asset] = true
-		supportedList = append(supportedList, asset)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
 	}
-	log.Printf("Loaded %d symbols", len(supportedAssets))
-	return supportedList
 }
 
 func main() {
-	// Load symbols from CSV
-	supportedList := loadSymbols("symbols.csv")
-	log.Printf("Loaded %d symbols", len(supportedAssets))
+	// Load tier definitions shared by the refresher, cache and handler
+	tierConfig := tiers.Default()
+	if path := os.Getenv("TIERS_CONFIG"); path != "" {
+		loaded, err := tiers.Load(path)
+		if err != nil {
+			log.Fatalf("Failed to load tier config: %v", err)
+		}
+		tierConfig = loaded
+	}
+	log.Printf("Using %d refresh tiers", len(tierConfig.Tiers))
+
+	// Load symbols and their metadata from CSV
+	symbolList, err := symbols.Load("symbols.csv", tierConfig)
+	if err != nil {
+		log.Fatalf("Failed to load symbols file: %v", err)
+	}
+	symbolRegistry := symbols.NewRegistry(symbolList)
+	supportedList := symbolRegistry.List()
+	log.Printf("Loaded %d symbols", symbolRegistry.Len())
 
 	// Get Redis connection info from environment variables or use defaults
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
 		redisAddr = "redis:6379" // Default for local development
 	}
+	redisConfig := redisclient.Config{
+		Mode:             os.Getenv("REDIS_MODE"),
+		Addrs:            strings.Split(redisAddr, ","),
+		MasterName:       os.Getenv("REDIS_MASTER_NAME"),
+		Username:         os.Getenv("REDIS_USERNAME"),
+		Password:         os.Getenv("REDIS_PASSWORD"),
+		SentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		DB:               intFromEnv("REDIS_DB", 0),
+		TLS:              boolFromEnv("REDIS_TLS", false),
+		TLSCAFile:        os.Getenv("REDIS_TLS_CA_FILE"),
+		TLSServerName:    os.Getenv("REDIS_TLS_SERVER_NAME"),
+		PoolSize:         intFromEnv("REDIS_POOL_SIZE", 0),
+		MinIdleConns:     intFromEnv("REDIS_MIN_IDLE_CONNS", 0),
+		DialTimeout:      durationFromEnv("REDIS_DIAL_TIMEOUT", 5*time.Second),
+		ReadTimeout:      durationFromEnv("REDIS_READ_TIMEOUT", 3*time.Second),
+		WriteTimeout:     durationFromEnv("REDIS_WRITE_TIMEOUT", 3*time.Second),
+	}
+
+	// Initialize Redis client for the configured topology
+	redisClient, err := redisclient.New(redisConfig)
+	if err != nil {
+		log.Fatalf("Invalid Redis configuration: %v", err)
+	}
 
-	// Initialize Redis client with appropriate address
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
+	// Every key and channel is namespaced so environments can share one Redis
+	keyPrefix := os.Getenv("REDIS_KEY_PREFIX")
 
-	// Test Redis connection
-	if _, err := redisClient.Ping(redisClient.Context()).Result(); err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+	// Test Redis connection; unless Redis is required, start degraded and
+	// serve from storage until it comes up
+	redisReady := true
+	if err := redisclient.Ping(redisClient, redisConfig.DialTimeout); err != nil {
+		if boolFromEnv("REDIS_REQUIRED", false) {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		log.Printf("Redis unavailable, starting in degraded mode: %v", err)
+		redisReady = false
 	}
 
 	// Initialize DynamoDB client
@@ -98,15 +201,72 @@ func main() {
 
 	systemMetrics.StartCollecting(5 * time.Second)
 
+	// Per-exchange request budget shared by the refresher, ForceRefresh and /refresh
+	rateLimit := fetcher.RateLimitConfig{
+		RequestsPerSecond: 50, // Default budget per exchange
+		Burst:             100,
+	}
+	if v := os.Getenv("EXCHANGE_RATE_LIMIT"); v != "" {
+		if rps, err := strconv.ParseFloat(v, 64); err == nil {
+			rateLimit.RequestsPerSecond = rps
+		} else {
+			log.Printf("Invalid EXCHANGE_RATE_LIMIT %q, using default: %v", v, err)
+		}
+	}
+	if v := os.Getenv("EXCHANGE_RATE_BURST"); v != "" {
+		if burst, err := strconv.Atoi(v); err == nil {
+			rateLimit.Burst = burst
+		} else {
+			log.Printf("Invalid EXCHANGE_RATE_BURST %q, using default: %v", v, err)
+		}
+	}
+
 	// Initialize Fetcher with environment-specific URLs
 	priceFetcher := fetcher.NewFetcher([]string{
 		exchange1,
 		exchange2,
 		exchange3,
-	}, metricsService)
+	}, rateLimit, metricsService)
 
 	// Initialize Cache and Storage
-	priceCache := cache.NewRedisCache(redisClient)
+	cacheCodec, err := cache.NewCodec(os.Getenv("CACHE_CODEC"))
+	if err != nil {
+		log.Fatalf("Invalid cache configuration: %v", err)
+	}
+	log.Printf("Encoding cache values as %s", cacheCodec.Name())
+	redisCache := cache.NewRedisCache(redisClient, tierConfig, keyPrefix, cacheCodec, metricsService)
+	// Cache entries outlive their refresh interval, with jitter so they don't expire in step
+	ttlPolicy := cache.DefaultTTLPolicy()
+	ttlPolicy.Multiplier = floatFromEnv("CACHE_TTL_MULTIPLIER", ttlPolicy.Multiplier)
+	ttlPolicy.Jitter = floatFromEnv("CACHE_TTL_JITTER", ttlPolicy.Jitter)
+	ttlPolicy.NearExpiry = durationFromEnv("CACHE_NEAR_EXPIRY", ttlPolicy.NearExpiry)
+	redisCache.SetTTLPolicy(ttlPolicy)
+	// The cache is optional: when Redis keeps failing the breaker opens and
+	// reads go straight to storage until it recovers
+	cacheBreaker := cache.NewBreakerCache(
+		redisCache,
+		redisClient,
+		durationFromEnv("CACHE_BREAKER_RESET", 10*time.Second),
+		metricsService,
+	)
+	var priceCache cache.Cache = cacheBreaker
+
+	// Keep hot prices in process in front of Redis; replicas invalidate each
+	// other's copies over pub/sub when they write
+	if l1Size := intFromEnv("L1_CACHE_SIZE", 1000); l1Size > 0 {
+		layeredCache := cache.NewLayeredCache(
+			priceCache,
+			redisClient,
+			keyPrefix+cache.InvalidationChannel,
+			leader.ReplicaID(),
+			l1Size,
+			durationFromEnv("L1_CACHE_TTL", time.Second),
+			metricsService,
+		)
+		layeredCache.Start()
+		defer layeredCache.Stop()
+		priceCache = layeredCache
+	}
 	priceStorage := storage.NewDynamoDBStorage(dynamoClient, systemMetrics)
 
 	// Initialize Refresher service
@@ -114,6 +274,7 @@ func main() {
 		priceFetcher,
 		priceCache,
 		priceStorage,
+		tierConfig,
 		supportedList,
 		metricsService,
 	)
@@ -121,9 +282,101 @@ func main() {
 	// Assign refresh tiers to assets based on popularity (order in CSV)
 	priceRefresher.AssignTiers()
 
-	// Start the auto-refresh service
-	priceRefresher.Start()
-	defer priceRefresher.Stop() // Ensure proper cleanup on shutdown
+	// Pin assets whose tier is set in symbols.csv
+	overrides := []symbols.Symbol{}
+	for _, s := range symbolRegistry.All() {
+		if s.Enabled && s.Tier != "" {
+			overrides = append(overrides, s)
+		}
+	}
+	priceRefresher.UpdateAssets(overrides)
+
+	// Back off failing assets and stop serving their prices as current after a while
+	healthPolicy := refresher.DefaultHealthPolicy()
+	healthPolicy.MaxBackoff = durationFromEnv("REFRESH_MAX_BACKOFF", healthPolicy.MaxBackoff)
+	healthPolicy.UnavailableAfter = durationFromEnv("ASSET_UNAVAILABLE_AFTER", healthPolicy.UnavailableAfter)
+	priceRefresher.SetHealthPolicy(healthPolicy)
+
+	// Announce every refresh on a per-asset channel and a bounded stream
+	streamLen := int64(100000)
+	if v := os.Getenv("PRICE_STREAM_MAXLEN"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			streamLen = n
+		} else {
+			log.Printf("Invalid PRICE_STREAM_MAXLEN %q, using default: %v", v, err)
+		}
+	}
+	publisher := events.NewPublisher(redisClient, keyPrefix, streamLen, metricsService)
+	publisher.SetHistoryLen(int64(intFromEnv("PRICE_HISTORY_LEN", 100)))
+	publisher.SetBreaker(cacheBreaker.Execute)
+	priceRefresher.SetPublisher(publisher)
+
+	// Reads that find stale data share one forced refresh per asset
+	priceRefresher.SetMinForceInterval(durationFromEnv("FORCE_REFRESH_MIN_INTERVAL", time.Second))
+
+	// Re-tier assets from real access patterns
+	tieringInterval := durationFromEnv("TIER_REBALANCE_INTERVAL", time.Minute)
+	tieringHalfLife := durationFromEnv("TIER_RATE_HALF_LIFE", 10*time.Minute)
+	startRefreshing := func() {
+		priceRefresher.Start()
+		priceRefresher.StartAdaptiveTiering(tieringInterval, tieringHalfLife)
+	}
+
+	// Decide which replicas run the refresher; stopCoordination hands this
+	// replica's work to the others on shutdown
+	stopCoordination := func() {}
+	switch mode := os.Getenv("REFRESH_COORDINATION"); mode {
+	case "leader":
+		// Only the replica holding the Redis lease refreshes; the others serve
+		// reads from the shared cache and take over when the lease expires
+		elector := leader.NewElector(
+			redisClient,
+			keyPrefix+"price-aggregator:refresher-leader",
+			durationFromEnv("LEADER_LEASE_TTL", 10*time.Second),
+			startRefreshing,
+			priceRefresher.Stop,
+			metricsService,
+		)
+		// Writes carry the term's fencing token so a deposed leader can't
+		// overwrite its successor's prices
+		priceRefresher.SetWriteGuard(func(string) (int64, bool) {
+			token := elector.Token()
+			return token, token > 0 && elector.IsLeader()
+		})
+		elector.Start()
+
+		// Release the lease on shutdown so a follower takes over immediately
+		stopCoordination = elector.Stop
+	case "sharded":
+		// Assets are spread over the live replicas with consistent hashing;
+		// each replica refreshes only the assets it owns on the ring
+		membership := cluster.NewMembership(
+			redisClient,
+			keyPrefix+"price-aggregator:members",
+			leader.ReplicaID(),
+			durationFromEnv("CLUSTER_MEMBER_TTL", 10*time.Second),
+			func(*cluster.Ring) { priceRefresher.Rebalance() },
+			metricsService,
+		)
+		priceRefresher.SetOwnership(membership.Owns)
+		priceRefresher.SetWriteGuard(func(asset string) (int64, bool) { return 0, membership.Owns(asset) })
+		startRefreshing()
+		membership.Start()
+
+		// Leave the cluster on shutdown so the others pick up our assets at once
+		stopCoordination = membership.Stop
+	case "", "none":
+		// Every replica refreshes every asset
+		startRefreshing()
+	default:
+		log.Fatalf("Unknown REFRESH_COORDINATION %q (expected none, leader or sharded)", mode)
+	}
+
+	// Reload symbols.csv on change, SIGHUP or admin request without restarting
+	symbolReloader := symbols.NewReloader("symbols.csv", symbolRegistry, tierConfig, priceRefresher)
+	// Refuse reloads that drop most of the universe, such as a half-written file
+	symbolReloader.SetMaxRemoval(floatFromEnv("SYMBOLS_MAX_REMOVAL", symbols.DefaultMaxRemoval))
+	symbolReloader.Watch(durationFromEnv("SYMBOLS_WATCH_INTERVAL", 10*time.Second))
 
 	// Initialize API Handler with the refresher
 	handler := api.NewHandler(
@@ -131,17 +384,82 @@ func main() {
 		priceCache,
 		priceStorage,
 		priceRefresher,
-		supportedAssets,
+		symbolRegistry,
+		symbolReloader,
 		metricsService,
 	)
-	handler.WarmupCache()
+	handler.AddReadinessCheck("cache", false, cacheBreaker.Check)
+	if redisReady {
+		handler.WarmupCache()
+	} else {
+		redisclient.WaitUntilReady(redisClient, 5*time.Second, handler.WarmupCache)
+	}
+
+	// Optionally serve stale prices immediately and refresh them in the background
+	staleLimitAction := os.Getenv("STALE_LIMIT_ACTION")
+	if staleLimitAction != "" && staleLimitAction != "wait" && staleLimitAction != "reject" {
+		log.Printf("Invalid STALE_LIMIT_ACTION %q, using default: wait", staleLimitAction)
+	}
+	handler.SetStaleWhileRevalidate(
+		boolFromEnv("STALE_WHILE_REVALIDATE", false),
+		durationFromEnv("STALE_HARD_LIMIT", 15*time.Minute),
+		staleLimitAction == "reject",
+	)
+	// Answer 503 for a while after fetching an asset with no data fails
+	handler.SetNegativeCacheTTL(durationFromEnv("NEGATIVE_CACHE_TTL", 30*time.Second))
+	handler.SetMaxBatchSize(intFromEnv("MAX_BATCH_ASSETS", 100))
+
+	// Push refreshed prices from every replica to streaming clients, which
+	// resume from the update stream after reconnecting
+	broker := events.NewBroker(redisClient, publisher, intFromEnv("STREAM_BUFFER_SIZE", 1000), metricsService)
+	broker.Start()
+	defer broker.Stop()
+	handler.SetBroker(broker, durationFromEnv("STREAM_HEARTBEAT", 15*time.Second))
+	if origins := os.Getenv("WS_ALLOWED_ORIGINS"); origins != "" {
+		handler.SetAllowedOrigins(strings.Split(origins, ","))
+	}
 
 	// Set up routes
 	r := mux.NewRouter()
+	r.NotFoundHandler = http.HandlerFunc(api.NotFound)
+	r.MethodNotAllowedHandler = http.HandlerFunc(api.MethodNotAllowed)
 
-	// Price API endpoints
-	r.HandleFunc("/prices/{asset}", handler.GetPrice).Methods("GET")
-	r.HandleFunc("/refresh/{asset}", handler.RefreshPrice).Methods("POST")
+	// The public API is served under /v1; the unversioned paths remain as
+	// deprecated aliases until clients have moved
+	publicRoutes := func(r *mux.Router) {
+		// Price API endpoints
+		r.HandleFunc("/prices", handler.GetPrices).Methods("GET")
+		r.HandleFunc("/prices/batch", handler.GetPricesBatch).Methods("POST")
+		r.HandleFunc("/prices/{asset}", handler.GetPrice).Methods("GET")
+		r.HandleFunc("/refresh/{asset}", handler.RefreshPrice).Methods("POST")
+		r.HandleFunc("/stream/prices", handler.StreamPrices).Methods("GET")
+		r.HandleFunc("/ws/prices", handler.PriceSocket).Methods("GET")
+
+		// Asset metadata endpoints
+		r.HandleFunc("/assets", handler.ListAssets).Methods("GET")
+		r.HandleFunc("/assets/{asset}", handler.GetAsset).Methods("GET")
+	}
+	publicRoutes(r.PathPrefix(api.APIVersionPrefix).Subrouter())
+	legacy := r.NewRoute().Subrouter()
+	legacy.Use(handler.Deprecated())
+	publicRoutes(legacy)
+
+	// Admin endpoints for inspecting and overriding asset tiers at runtime;
+	// they are only served when a token protects them
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		admin := r.PathPrefix("/admin").Subrouter()
+		admin.Use(api.AdminAuth(adminToken))
+		admin.HandleFunc("/assets", handler.ListAssetStatuses).Methods("GET")
+		admin.HandleFunc("/assets/{asset}", handler.GetAssetStatus).Methods("GET")
+		admin.HandleFunc("/assets/{asset}/tier", handler.PinAssetTier).Methods("PUT")
+		admin.HandleFunc("/assets/{asset}/tier", handler.UnpinAssetTier).Methods("DELETE")
+		admin.HandleFunc("/assets/{asset}/pause", handler.PauseAsset).Methods("POST")
+		admin.HandleFunc("/assets/{asset}/resume", handler.ResumeAsset).Methods("POST")
+		admin.HandleFunc("/assets/{asset}/refresh", handler.TriggerAssetRefresh).Methods("POST")
+		admin.HandleFunc("/symbols/reload", handler.ReloadSymbols).Methods("POST")
+	} else {
+		log.Println("ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
 
 	// Health check endpoint
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -149,16 +467,84 @@ func main() {
 		w.Write([]byte("OK"))
 	}).Methods("GET")
 
+	// Readiness reports dependency health; a cache outage only degrades it
+	r.HandleFunc("/ready", handler.Ready).Methods("GET")
+
 	// Prometheus metrics endpoint
 	r.Handle("/metrics", promhttp.Handler())
 
+	// Serve the same API over gRPC for internal services
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(handler.UnaryMetrics()),
+		grpc.ChainStreamInterceptor(handler.StreamMetrics()),
+	)
+	pricepb.RegisterPriceServiceServer(grpcServer, api.NewGRPCService(handler, publisher))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":50051"
+	}
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC on %s: %v", grpcAddr, err)
+	}
+	go func() {
+		log.Printf("Starting gRPC server on %s...", grpcAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
+	// Report the gRPC health from the same checks as GET /ready
+	healthStop := make(chan struct{})
+	go reportGRPCHealth(healthServer, handler.IsReady, durationFromEnv("GRPC_HEALTH_INTERVAL", 5*time.Second), healthStop)
+
 	// increase the GC percent to 200% for testing
 	debug.SetGCPercent(200)
 
+	server := &http.Server{Addr: ":8080", Handler: api.RequestID(r)}
+
+	// On SIGINT or SIGTERM stop taking traffic, hand refreshing over to the
+	// other replicas and let in-flight requests finish
+	shutdownTimeout := durationFromEnv("SHUTDOWN_TIMEOUT", 10*time.Second)
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		shutdown := make(chan os.Signal, 1)
+		signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+		<-shutdown
+		log.Println("Shutting down...")
+
+		close(healthStop)
+		healthServer.Shutdown() // NOT_SERVING for every service
+		stopCoordination()
+		priceRefresher.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		// Streams outlive any timeout, so cut what is left when it expires
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("HTTP server shutdown: %v", err)
+		}
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}()
+
 	// Start server
 	log.Println("Starting server on port 8080...")
-	if err := http.ListenAndServe(":8080", r); err != nil {
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-	log.Fatal(http.ListenAndServe(":8080", r))
+	<-shutdownDone
 }
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"real-time-price-aggregator/internal/fetcher"
+	"real-time-price-aggregator/internal/refresher"
+	"real-time-price-aggregator/internal/symbols"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/gorilla/mux"
+)
+
+// assetStatusResponse is the admin view of an asset's refresh state
+type assetStatusResponse struct {
+	Asset       string `json:"asset"`
+	Tier        string `json:"tier"`
+	Pinned      bool   `json:"pinned"`
+	Paused      bool   `json:"paused"`
+	LastRefresh string `json:"last_refresh,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+	LastErrorAt string `json:"last_error_at,omitempty"`
+	NextRefresh string `json:"next_refresh,omitempty"`
+	Health      string `json:"health"`
+	Failures    int    `json:"consecutive_failures"`
+}
+
+// setTierRequest is the body of PUT /admin/assets/{asset}/tier
+type setTierRequest struct {
+	Tier string `json:"tier"`
+}
+
+// formatOptionalTime formats a time for the admin API, leaving zero times empty
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return types.FormatTimestamp(t.Unix())
+}
+
+// toStatusResponse converts a refresher status to its JSON form
+func toStatusResponse(s refresher.AssetStatus) assetStatusResponse {
+	return assetStatusResponse{
+		Asset:       s.Asset,
+		Tier:        s.Tier,
+		Pinned:      s.Pinned,
+		Paused:      s.Paused,
+		LastRefresh: formatOptionalTime(s.LastRefresh),
+		LastError:   s.LastError,
+		LastErrorAt: formatOptionalTime(s.LastErrorAt),
+		NextRefresh: formatOptionalTime(s.NextRefresh),
+		Health:      string(s.Health),
+		Failures:    s.Failures,
+	}
+}
+
+// AdminAuth requires a bearer token on admin endpoints; without a token
+// every request is refused
+func AdminAuth(token string) mux.MiddlewareFunc {
+	expected := []byte("Bearer " + token)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := []byte(r.Header.Get("Authorization"))
+			if token == "" || subtle.ConstantTimeCompare(given, expected) != 1 {
+				respondWithError(w, http.StatusUnauthorized, codeUnauthorized, "Unauthorized")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// respondWithAdminError maps refresher errors to HTTP responses
+func respondWithAdminError(w http.ResponseWriter, asset string, err error) {
+	switch {
+	case errors.Is(err, fetcher.ErrAssetNotSupported):
+		respondWithError(w, http.StatusNotFound, codeAssetNotFound, "Asset not found")
+	case errors.Is(err, refresher.ErrUnknownTier):
+		respondWithError(w, http.StatusBadRequest, codeUnknownTier, "Unknown tier")
+	case errors.Is(err, fetcher.ErrRateLimited):
+		respondWithError(w, http.StatusTooManyRequests, codeRateLimited, "Exchange request budget exhausted, try again later")
+	default:
+		log.Printf("Admin operation failed for %s: %v", asset, err)
+		respondWithError(w, http.StatusInternalServerError, codeInternal, "Internal server error")
+	}
+}
+
+// ListAssetStatuses handles GET /admin/assets
+// Optional ?tier= and ?health= parameters filter by tier name and health state
+func (h *Handler) ListAssetStatuses(w http.ResponseWriter, r *http.Request) {
+	tierFilter := r.URL.Query().Get("tier")
+	healthFilter := r.URL.Query().Get("health")
+
+	statuses := h.refresher.GetAllAssetStatuses()
+	response := make([]assetStatusResponse, 0, len(statuses))
+	for _, s := range statuses {
+		if tierFilter != "" && s.Tier != tierFilter {
+			continue
+		}
+		if healthFilter != "" && string(s.Health) != healthFilter {
+			continue
+		}
+		response = append(response, toStatusResponse(s))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetAssetStatus handles GET /admin/assets/{asset}
+func (h *Handler) GetAssetStatus(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+	status, err := h.refresher.GetAssetStatus(asset)
+	if err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toStatusResponse(status))
+}
+
+// PinAssetTier handles PUT /admin/assets/{asset}/tier
+func (h *Handler) PinAssetTier(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+
+	var req setTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tier == "" {
+		respondWithError(w, http.StatusBadRequest, codeInvalidRequest, "Request body must be {\"tier\": \"<name>\"}")
+		return
+	}
+
+	if err := h.refresher.PinTier(asset, req.Tier); err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	h.GetAssetStatus(w, r)
+}
+
+// UnpinAssetTier handles DELETE /admin/assets/{asset}/tier
+func (h *Handler) UnpinAssetTier(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+	if err := h.refresher.UnpinTier(asset); err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	h.GetAssetStatus(w, r)
+}
+
+// PauseAsset handles POST /admin/assets/{asset}/pause
+func (h *Handler) PauseAsset(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+	if err := h.refresher.Pause(asset); err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	h.GetAssetStatus(w, r)
+}
+
+// ResumeAsset handles POST /admin/assets/{asset}/resume
+func (h *Handler) ResumeAsset(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+	if err := h.refresher.Resume(asset); err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	h.GetAssetStatus(w, r)
+}
+
+// TriggerAssetRefresh handles POST /admin/assets/{asset}/refresh
+func (h *Handler) TriggerAssetRefresh(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+	if err := h.refresher.ForceRefresh(asset); err != nil {
+		respondWithAdminError(w, asset, err)
+		return
+	}
+	h.GetAssetStatus(w, r)
+}
+
+// ReloadSymbols handles POST /admin/symbols/reload
+// ?allow_mass_removal=true applies a file that removes most or all assets
+func (h *Handler) ReloadSymbols(w http.ResponseWriter, r *http.Request) {
+	opts := symbols.ReloadOptions{AllowMassRemoval: r.URL.Query().Get("allow_mass_removal") == "true"}
+	result, err := h.reloader.Reload(opts)
+	if errors.Is(err, symbols.ErrMassRemoval) {
+		respondWithError(w, http.StatusConflict, codeMassRemoval, err.Error()+"; retry with ?allow_mass_removal=true to apply it")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to reload symbols: %v", err)
+		respondWithError(w, http.StatusInternalServerError, codeInternal, "Failed to reload symbols: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"real-time-price-aggregator/internal/symbols"
+
+	"github.com/gorilla/mux"
+)
+
+// Pagination limits for GET /assets
+const (
+	defaultAssetPageSize = 100
+	maxAssetPageSize     = 1000
+)
+
+// assetResponse is a symbol's metadata plus its current refresh tier
+type assetResponse struct {
+	symbols.Symbol
+	RefreshTier string `json:"refresh_tier,omitempty"`
+	Health      string `json:"health,omitempty"`
+}
+
+// assetListResponse is a page of GET /assets results
+type assetListResponse struct {
+	Assets []assetResponse `json:"assets"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// toAssetResponse adds the live refresh tier to a symbol; disabled assets have none
+func (h *Handler) toAssetResponse(s symbols.Symbol) assetResponse {
+	resp := assetResponse{Symbol: s}
+	if s.Enabled {
+		resp.RefreshTier = h.refresher.GetAssetTier(s.Symbol).Name
+		resp.Health = string(h.refresher.GetAssetHealth(s.Symbol))
+	}
+	return resp
+}
+
+// parseNonNegative reads an optional non-negative integer query parameter
+func parseNonNegative(value string, fallback int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// ListAssets handles GET /assets
+// Filters: asset_class, quote_currency, venue, tier, enabled
+// Pagination: limit (default 100, max 1000) and offset
+func (h *Handler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, ok := parseNonNegative(query.Get("limit"), defaultAssetPageSize)
+	if !ok || limit == 0 {
+		respondWithError(w, http.StatusBadRequest, codeInvalidRequest, "limit must be a positive integer")
+		return
+	}
+	if limit > maxAssetPageSize {
+		limit = maxAssetPageSize
+	}
+	offset, ok := parseNonNegative(query.Get("offset"), 0)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, codeInvalidRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	var enabledFilter *bool
+	if v := query.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, codeInvalidRequest, "enabled must be true or false")
+			return
+		}
+		enabledFilter = &enabled
+	}
+
+	assetClass := strings.ToLower(query.Get("asset_class"))
+	quoteCurrency := strings.ToUpper(query.Get("quote_currency"))
+	venue := query.Get("venue")
+	tier := query.Get("tier")
+
+	matched := []assetResponse{}
+	for _, s := range h.symbols.All() {
+		if assetClass != "" && s.AssetClass != assetClass {
+			continue
+		}
+		if quoteCurrency != "" && s.QuoteCurrency != quoteCurrency {
+			continue
+		}
+		if venue != "" && !s.HasVenue(venue) {
+			continue
+		}
+		if enabledFilter != nil && s.Enabled != *enabledFilter {
+			continue
+		}
+
+		resp := h.toAssetResponse(s)
+		if tier != "" && resp.RefreshTier != tier {
+			continue
+		}
+		matched = append(matched, resp)
+	}
+
+	// Slice out the requested page
+	page := []assetResponse{}
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page = matched[offset:end]
+	}
+
+	respondWithJSON(w, http.StatusOK, assetListResponse{
+		Assets: page,
+		Total:  len(matched),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetAsset handles GET /assets/{asset}
+func (h *Handler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(mux.Vars(r)["asset"])
+
+	s, ok := h.symbols.Get(asset)
+	if !ok {
+		respondWithAPIError(w, h.unsupportedAssetError(asset))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, h.toAssetResponse(s))
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"real-time-price-aggregator/internal/types"
+)
+
+// priceETag identifies a price by asset and timestamp. It is weak because
+// time_ago changes between otherwise equal responses, and marks stale or
+// unhealthy prices so clients don't keep a copy without the warning
+func priceETag(p *types.PriceDataResponse) string {
+	tag := p.Asset + "-" + strconv.FormatInt(p.Timestamp, 10)
+	if p.Health != "" {
+		tag += "-" + p.Health
+	}
+	if p.Stale {
+		tag += "-stale"
+	}
+	return `W/"` + tag + `"`
+}
+
+// priceMaxAge is how long a price may be cached: until its tier's next
+// refresh is due, or not at all once that has passed
+func priceMaxAge(p *types.PriceDataResponse, refreshInterval time.Duration) time.Duration {
+	if p.Stale {
+		return 0
+	}
+	remaining := refreshInterval - time.Since(time.Unix(p.Timestamp, 0))
+	if remaining < 0 {
+		return 0
+	}
+	if remaining > refreshInterval {
+		return refreshInterval // timestamp in the future
+	}
+	return remaining
+}
+
+// setPriceCacheHeaders sets ETag, Last-Modified and Cache-Control for a price
+func setPriceCacheHeaders(w http.ResponseWriter, p *types.PriceDataResponse, refreshInterval time.Duration) {
+	w.Header().Set("ETag", priceETag(p))
+	w.Header().Set("Last-Modified", time.Unix(p.Timestamp, 0).UTC().Format(http.TimeFormat))
+	maxAge := int(priceMaxAge(p, refreshInterval).Seconds())
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+}
+
+// notModified reports whether the client's copy of a price is current:
+// If-None-Match is checked if present, otherwise If-Modified-Since
+func notModified(r *http.Request, p *types.PriceDataResponse) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		etag := strings.TrimPrefix(priceETag(p), "W/")
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match uses the weak comparison
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && p.Timestamp <= t.Unix()
+	}
+	return false
+}
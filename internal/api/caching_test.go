@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"real-time-price-aggregator/internal/types"
+)
+
+func TestPriceETag(t *testing.T) {
+	tests := []struct {
+		name  string
+		price types.PriceDataResponse
+		want  string
+	}{
+		{name: "fresh", price: types.PriceDataResponse{Asset: "btc", Timestamp: 1700000000}, want: `W/"btc-1700000000"`},
+		{name: "unhealthy", price: types.PriceDataResponse{Asset: "btc", Timestamp: 1700000000, Health: "degraded"}, want: `W/"btc-1700000000-degraded"`},
+		{name: "stale", price: types.PriceDataResponse{Asset: "btc", Timestamp: 1700000000, Stale: true}, want: `W/"btc-1700000000-stale"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceETag(&tt.price); got != tt.want {
+				t.Errorf("priceETag = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const timestamp = 1700000000
+	modified := time.Unix(timestamp, 0).UTC()
+	price := &types.PriceDataResponse{Asset: "btc", Timestamp: timestamp}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "no validators", want: false},
+		{name: "weak match", headers: map[string]string{"If-None-Match": `W/"btc-1700000000"`}, want: true},
+		{name: "strong tag matches weakly", headers: map[string]string{"If-None-Match": `"btc-1700000000"`}, want: true},
+		{name: "one of several", headers: map[string]string{"If-None-Match": `W/"btc-1", W/"btc-1700000000"`}, want: true},
+		{name: "wildcard", headers: map[string]string{"If-None-Match": "*"}, want: true},
+		{name: "older tag", headers: map[string]string{"If-None-Match": `W/"btc-1699999995"`}, want: false},
+		{name: "unquoted tag", headers: map[string]string{"If-None-Match": "btc-1700000000"}, want: false},
+		{name: "stale copy", headers: map[string]string{"If-None-Match": `W/"btc-1700000000-stale"`}, want: false},
+		{name: "modified since", headers: map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, want: false},
+		{name: "not modified since", headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, want: true},
+		{name: "invalid date", headers: map[string]string{"If-Modified-Since": "yesterday"}, want: false},
+		{
+			// If-Modified-Since is ignored when If-None-Match is present
+			name: "etag takes precedence",
+			headers: map[string]string{
+				"If-None-Match":     `W/"btc-1"`,
+				"If-Modified-Since": modified.Format(http.TimeFormat),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/prices/btc", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			if got := notModified(r, price); got != tt.want {
+				t.Errorf("notModified = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Machine-readable error codes; clients should branch on these rather than
+// on messages, which may change
+const (
+	codeInvalidRequest    = "invalid_request"
+	codeTooManyAssets     = "too_many_assets"
+	codeUnknownTier       = "unknown_tier"
+	codeUnauthorized      = "unauthorized"
+	codeMassRemoval       = "mass_removal" // a symbols reload would remove too many assets
+	codeNotFound          = "not_found"    // no such route
+	codeMethodNotAllowed  = "method_not_allowed"
+	codeAssetNotFound     = "asset_not_found"
+	codeAssetDisabled     = "asset_disabled"
+	codeAssetDelisted     = "asset_delisted"
+	codeAssetUnavailable  = "asset_unavailable" // refreshes have been failing for too long
+	codePriceNotAvailable = "price_not_available"
+	codePriceTooStale     = "price_too_stale"
+	codeRateLimited       = "rate_limited"
+	codeStreamingDisabled = "streaming_disabled"
+	codeInternal          = "internal_error"
+)
+
+// apiError is a failed request as the HTTP API reports it: the status, a
+// code and message, optional details and, if set, the Retry-After in seconds
+type apiError struct {
+	Status     int
+	Code       string
+	Message    string
+	Details    map[string]interface{}
+	RetryAfter int
+}
+
+// errorResponse is the JSON body of every error:
+//
+//	{"error": {"code": "asset_not_found", "message": "Asset not found", "request_id": "...", "details": {...}}}
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// newAPIError creates an error without details
+func newAPIError(status int, code, msg string) *apiError {
+	return &apiError{Status: status, Code: code, Message: msg, Details: map[string]interface{}{}}
+}
+
+// Error returns the message
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// respondWithAPIError sends an apiError, tagged with the request ID that
+// RequestID put on the response. Errors are never cached, so a CDN doesn't
+// keep serving one after the asset recovers
+func respondWithAPIError(w http.ResponseWriter, apiErr *apiError) {
+	w.Header().Set("Cache-Control", "no-store")
+	if apiErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(apiErr.RetryAfter))
+	}
+	respondWithJSON(w, apiErr.Status, errorResponse{Error: errorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: w.Header().Get(requestIDHeader),
+		Details:   apiErr.Details,
+	}})
+}
+
+// respondWithError sends an error response with the specified status code, error code and message
+func respondWithError(w http.ResponseWriter, status int, code, message string) {
+	respondWithAPIError(w, newAPIError(status, code, message))
+}
+
+// NotFound answers requests that match no route
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	respondWithError(w, http.StatusNotFound, codeNotFound, "Not found")
+}
+
+// MethodNotAllowed answers requests whose path exists for other methods
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	respondWithError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+}
+
+// upgradeError answers a failed WebSocket handshake
+func upgradeError(w http.ResponseWriter, r *http.Request, status int, reason error) {
+	respondWithError(w, status, codeInvalidRequest, reason.Error())
+}
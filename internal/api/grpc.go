@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"real-time-price-aggregator/internal/events"
+	"real-time-price-aggregator/internal/pricepb"
+	"real-time-price-aggregator/internal/types"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// defaultHistoryLimit is the number of updates GetHistory returns by default
+const defaultHistoryLimit = 100
+
+// assetRequiredError answers single-asset calls whose asset is blank with
+// the HTTP routes' 400
+func assetRequiredError() error {
+	return grpcError(newAPIError(http.StatusBadRequest, codeInvalidRequest, "Asset symbol is required"))
+}
+
+// GRPCService serves pricepb.PriceService with the same lookups as the HTTP
+// handlers, so both APIs behave alike
+type GRPCService struct {
+	pricepb.UnimplementedPriceServiceServer
+
+	handler   *Handler
+	publisher *events.Publisher
+}
+
+// NewGRPCService creates the gRPC service; history is read through publisher
+func NewGRPCService(h *Handler, p *events.Publisher) *GRPCService {
+	return &GRPCService{handler: h, publisher: p}
+}
+
+// GetPrice returns the current price of an asset
+func (s *GRPCService) GetPrice(ctx context.Context, req *pricepb.GetPriceRequest) (*pricepb.Price, error) {
+	asset := normalizeAsset(req.GetAsset())
+	if asset == "" {
+		return nil, assetRequiredError()
+	}
+	priceResponse, apiErr := s.handler.lookupPrice(asset)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	return toPricepb(priceResponse), nil
+}
+
+// BatchGetPrices returns the prices of several assets
+func (s *GRPCService) BatchGetPrices(ctx context.Context, req *pricepb.BatchGetPricesRequest) (*pricepb.BatchGetPricesResponse, error) {
+	assets := normalizeAssets(req.GetAssets())
+	if len(assets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "At least one asset is required")
+	}
+	if s.handler.maxBatchSize > 0 && len(assets) > s.handler.maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, "Too many assets requested")
+	}
+
+	entries, err := s.handler.batchPrices(assets)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+	response := &pricepb.BatchGetPricesResponse{Prices: make([]*pricepb.BatchPriceEntry, 0, len(entries))}
+	for _, entry := range entries {
+		response.Prices = append(response.Prices, &pricepb.BatchPriceEntry{
+			Asset:  entry.Asset,
+			Status: batchStatuses[entry.Status],
+			Reason: entry.Reason,
+			Price:  toPricepb(entry.PriceDataResponse),
+		})
+	}
+	return response, nil
+}
+
+// batchStatuses maps batch entry statuses to their protobuf values
+var batchStatuses = map[string]pricepb.BatchPriceEntry_Status{
+	batchStatusOK:          pricepb.BatchPriceEntry_STATUS_OK,
+	batchStatusStale:       pricepb.BatchPriceEntry_STATUS_STALE,
+	batchStatusUnsupported: pricepb.BatchPriceEntry_STATUS_UNSUPPORTED,
+	batchStatusUnavailable: pricepb.BatchPriceEntry_STATUS_UNAVAILABLE,
+}
+
+// RefreshPrice forces a refresh of an asset
+func (s *GRPCService) RefreshPrice(ctx context.Context, req *pricepb.RefreshPriceRequest) (*pricepb.RefreshPriceResponse, error) {
+	asset := normalizeAsset(req.GetAsset())
+	if asset == "" {
+		return nil, assetRequiredError()
+	}
+	if apiErr := s.handler.refreshPrice(asset); apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	return &pricepb.RefreshPriceResponse{Message: "Price for " + asset + " refreshed"}, nil
+}
+
+// GetHistory returns recent updates of an asset from the update stream
+func (s *GRPCService) GetHistory(ctx context.Context, req *pricepb.GetHistoryRequest) (*pricepb.GetHistoryResponse, error) {
+	asset := normalizeAsset(req.GetAsset())
+	if asset == "" {
+		return nil, assetRequiredError()
+	}
+	if !s.handler.symbols.IsSupported(asset) {
+		return nil, grpcError(s.handler.unsupportedAssetError(asset))
+	}
+	if s.publisher == nil || s.publisher.HistoryLen() <= 0 {
+		return nil, status.Error(codes.Unimplemented, "Price history is not enabled")
+	}
+
+	limit := int64(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > s.publisher.HistoryLen() {
+		limit = s.publisher.HistoryLen()
+	}
+	updates, err := s.publisher.History(asset, limit)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "Price history is temporarily unavailable")
+	}
+
+	tier := s.handler.refresher.GetAssetTier(asset).Name
+	response := &pricepb.GetHistoryResponse{Updates: make([]*pricepb.PriceUpdate, 0, len(updates))}
+	for _, update := range updates {
+		if update.Tier == "" {
+			update.Tier = tier
+		}
+		priceResponse := update.Data.ToResponseWithTier(update.Tier)
+		response.Updates = append(response.Updates, &pricepb.PriceUpdate{
+			Id:    update.ID,
+			Price: toPricepb(&priceResponse),
+		})
+	}
+	return response, nil
+}
+
+// SubscribePrices streams refreshed prices until the client goes away
+func (s *GRPCService) SubscribePrices(req *pricepb.SubscribePricesRequest, stream pricepb.PriceService_SubscribePricesServer) error {
+	h := s.handler
+	if h.broker == nil {
+		return status.Error(codes.Unavailable, "Price streaming is not enabled")
+	}
+	assets := normalizeAssets(req.GetAssets())
+	if h.maxBatchSize > 0 && len(assets) > h.maxBatchSize {
+		return status.Error(codes.InvalidArgument, "Too many assets requested")
+	}
+	for _, asset := range assets {
+		if !h.symbols.IsSupported(asset) {
+			return grpcError(h.unsupportedAssetError(asset))
+		}
+	}
+	sub, resumed := h.broker.Subscribe(assets, req.GetLastEventId())
+	defer h.broker.Unsubscribe(sub)
+	if !resumed {
+		if err := stream.Send(&pricepb.PriceUpdate{MissedUpdates: true}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.Ready():
+			for _, event := range sub.Take() {
+				priceResponse := event.Data.ToResponseWithTier(h.refresher.GetAssetTier(event.Data.Asset).Name)
+				err := stream.Send(&pricepb.PriceUpdate{
+					Id:    event.ID,
+					Price: toPricepb(&priceResponse),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// UnaryMetrics records unary gRPC calls like HTTP requests
+func (h *Handler) UnaryMetrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+		h.metrics.RecordGRPCRequest(info.FullMethod, status.Code(err).String())
+		h.metrics.ObserveAPIRequestDuration(info.FullMethod, time.Since(startTime))
+		return resp, err
+	}
+}
+
+// StreamMetrics records streaming gRPC calls when they end
+func (h *Handler) StreamMetrics() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		h.metrics.RecordGRPCRequest(info.FullMethod, status.Code(err).String())
+		return err
+	}
+}
+
+// normalizeAsset lowercases a symbol like the HTTP routes do
+func normalizeAsset(symbol string) string {
+	if assets := normalizeAssets([]string{symbol}); len(assets) > 0 {
+		return assets[0]
+	}
+	return ""
+}
+
+// toPricepb converts an API price to protobuf; nil stays nil
+func toPricepb(p *types.PriceDataResponse) *pricepb.Price {
+	if p == nil {
+		return nil
+	}
+	return &pricepb.Price{
+		Asset:       p.Asset,
+		Price:       p.Price,
+		LastUpdated: p.Timestamp,
+		RefreshTier: p.RefreshTier,
+		Health:      p.Health,
+		Stale:       p.Stale,
+	}
+}
+
+// grpcCodes maps the HTTP statuses of API errors to gRPC codes
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusGone:               codes.NotFound,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// grpcError converts an API error to a gRPC status, with the error code and
+// details as ErrorInfo and Retry-After as RetryInfo
+func grpcError(apiErr *apiError) error {
+	code, ok := grpcCodes[apiErr.Status]
+	if !ok {
+		code = codes.Internal
+	}
+	st := status.New(code, apiErr.Error())
+
+	info := &errdetails.ErrorInfo{Reason: apiErr.Code, Domain: pricepb.PriceService_ServiceDesc.ServiceName}
+	if len(apiErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(apiErr.Details))
+		for key, value := range apiErr.Details {
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+	details := []protoadapt.MessageV1{info}
+	if apiErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(apiErr.RetryAfter) * time.Second)})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -2,34 +2,63 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"real-time-price-aggregator/internal/cache"
+	"real-time-price-aggregator/internal/events"
 	"real-time-price-aggregator/internal/fetcher"
 	"real-time-price-aggregator/internal/metrics"
 	"real-time-price-aggregator/internal/refresher"
 	"real-time-price-aggregator/internal/storage"
+	"real-time-price-aggregator/internal/symbols"
 	"real-time-price-aggregator/internal/types"
 
 	"github.com/panjf2000/ants/v2"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 )
 
 // Handler handles API requests
 type Handler struct {
-	fetcher         fetcher.Fetcher
-	cache           cache.Cache
-	storage         storage.Storage
-	refresher       *refresher.Refresher
-	supportedAssets map[string]bool
-	metrics         *metrics.MetricsService
-	pool            *ants.Pool
-	// Maximum age of data before forcing a refresh (for cold tier assets)
-	maxDataAge time.Duration
+	fetcher   fetcher.Fetcher
+	cache     cache.Cache
+	storage   storage.Storage
+	refresher *refresher.Refresher
+	symbols   *symbols.Registry
+	reloader  *symbols.Reloader
+	metrics   *metrics.MetricsService
+	pool      *ants.Pool
+
+	// Stale-while-revalidate: serve stale data at once and refresh in the
+	// background, unless the data is older than staleLimit
+	staleWhileRevalidate bool
+	staleLimit           time.Duration
+	rejectBeyondLimit    bool // answer 503 instead of waiting for a refresh
+
+	// How long an asset nothing could be fetched for is answered with 503
+	// before fetching is tried again; 0 disables negative caching
+	negativeTTL time.Duration
+
+	maxBatchSize int // most assets in one batch price request; 0 means no limit
+
+	// Live price streaming; nil broker disables it
+	broker    *events.Broker
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+
+	readinessChecks []readinessCheck
+}
+
+// readinessCheck is a dependency probed by GET /ready
+type readinessCheck struct {
+	name     string
+	critical bool
+	check    func() error
 }
 
 // statusRecorder is a custom http.ResponseWriter to capture the status code
@@ -44,22 +73,43 @@ func NewHandler(
 	c cache.Cache,
 	s storage.Storage,
 	r *refresher.Refresher,
-	supportedAssets map[string]bool,
+	reg *symbols.Registry,
+	reloader *symbols.Reloader,
 	m *metrics.MetricsService,
 ) *Handler {
-	pool, _ := ants.NewPool(100) // Create a pool with 100 goroutines
+	// Create a pool with 100 goroutines; submissions fail instead of blocking
+	// a request when all of them are busy
+	pool, _ := ants.NewPool(100, ants.WithNonblocking(true))
 	return &Handler{
-		fetcher:         f,
-		cache:           c,
-		storage:         s,
-		refresher:       r,
-		supportedAssets: supportedAssets,
-		metrics:         m,
-		maxDataAge:      5 * time.Minute, // Maximum acceptable age for cold tier data
-		pool:            pool,
+		fetcher:   f,
+		cache:     c,
+		storage:   s,
+		refresher: r,
+		symbols:   reg,
+		reloader:  reloader,
+		metrics:   m,
+		pool:      pool,
+
+		maxBatchSize: maxBatchAssets,
+		upgrader:     websocket.Upgrader{Error: upgradeError},
 	}
 }
 
+// SetStaleWhileRevalidate makes GetPrice return stale data immediately and
+// refresh it in the background. Data older than limit (0 means no limit) is
+// refreshed before responding, or rejected with 503 if reject is set
+func (h *Handler) SetStaleWhileRevalidate(enabled bool, limit time.Duration, reject bool) {
+	h.staleWhileRevalidate = enabled
+	h.staleLimit = limit
+	h.rejectBeyondLimit = reject
+}
+
+// SetNegativeCacheTTL sets how long GetPrice remembers that an asset with no
+// data at all could not be fetched, instead of retrying on every request
+func (h *Handler) SetNegativeCacheTTL(ttl time.Duration) {
+	h.negativeTTL = ttl
+}
+
 // WriteHeader captures the status code
 func (r *statusRecorder) WriteHeader(status int) {
 	r.status = status
@@ -83,39 +133,58 @@ func (h *Handler) GetPrice(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	symbol := vars["asset"]
 	if symbol == "" {
-		respondWithError(&recorder, http.StatusBadRequest, "Asset symbol is required")
+		respondWithError(&recorder, http.StatusBadRequest, codeInvalidRequest, "Asset symbol is required")
 		return
 	}
 
 	// Convert to lowercase for case-insensitive comparison
 	symbolLower := strings.ToLower(symbol)
+	priceResponse, apiErr := h.lookupPrice(symbolLower)
+	if apiErr != nil {
+		respondWithAPIError(&recorder, apiErr)
+		return
+	}
 
-	// Check if asset is supported (in CSV)
-	if !h.supportedAssets[symbolLower] {
-		respondWithError(w, http.StatusBadRequest, "Invalid asset symbol")
+	// Let CDNs and polling clients keep the price until the next refresh
+	// and revalidate it without downloading it again
+	setPriceCacheHeaders(w, priceResponse, h.refresher.GetAssetTier(symbolLower).RefreshInterval.Duration)
+	if priceResponse.Stale {
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+	}
+	if notModified(r, priceResponse) {
+		recorder.WriteHeader(http.StatusNotModified)
 		return
 	}
+	respondWithJSON(&recorder, http.StatusOK, priceResponse)
+}
 
-	tier := h.refresher.GetAssetTier(symbolLower)
-	var tierString string
-	switch tier {
-	case refresher.HotTier:
-		tierString = "hot"
-	case refresher.MediumTier:
-		tierString = "medium"
-	case refresher.ColdTier:
-		tierString = "cold"
-	default:
-		tierString = "medium" // default to medium if not found
+// lookupPrice returns the current price of an asset for GET /prices/{asset}
+// and the gRPC GetPrice: from the cache, then storage, refreshing it from the
+// exchanges when it is missing or older than its tier allows
+func (h *Handler) lookupPrice(symbolLower string) (*types.PriceDataResponse, *apiError) {
+	// Check if asset is supported (in CSV)
+	if !h.symbols.IsSupported(symbolLower) {
+		return nil, h.unsupportedAssetError(symbolLower)
 	}
+
+	// Feed real demand into adaptive tiering
+	h.refresher.RecordAccess(symbolLower)
+
+	// Each tier defines how old its data may be before a read forces a refresh
+	tier := h.refresher.GetAssetTier(symbolLower)
+	tierString := tier.Name
+	maxDataAge := tier.MaxDataAge.Duration
+
+	health := h.refresher.GetAssetHealth(symbolLower)
+
 	// Check if asset is supported
 	var priceData *types.PriceData
 	var err error
 	priceData, err = h.cache.Get(symbolLower)
 	if err != nil {
+		// Redis may be down; treat it as a miss and serve from storage
 		log.Printf("Failed to get price from cache for %s: %v", symbolLower, err)
-		respondWithError(w, http.StatusInternalServerError, "Internal server error")
-		return
+		priceData = nil
 	}
 
 	// Check if we need to trigger a refresh
@@ -124,20 +193,19 @@ func (h *Handler) GetPrice(w http.ResponseWriter, r *http.Request) {
 	// Cache miss - try to get from storage and trigger refresh
 	if priceData == nil {
 		h.metrics.RecordCacheMiss()
+		// A recent fetch already failed; don't hit storage and the exchanges again
+		if apiErr := h.negativeError(symbolLower); apiErr != nil {
+			return nil, apiErr
+		}
 		// Try to get from storage
 		record, err := h.storage.Get(symbolLower)
 		if err != nil {
+			// Fetch directly from the exchanges as a last resort
 			log.Printf("Failed to get price from storage for %s: %v", symbolLower, err)
-			respondWithError(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
-
-		if record == nil {
+			needsRefresh = true
+		} else if record == nil {
 			// Neither in cache nor storage - trigger refresh
 			needsRefresh = true
-			if err := h.cache.Set(symbolLower, priceData, tierString); err != nil {
-				log.Printf("Failed to update cache from storage for %s: %v", symbolLower, err)
-			}
 		} else {
 			// Found in storage but not in cache - convert and check age
 			priceData = &types.PriceData{
@@ -153,45 +221,84 @@ func (h *Handler) GetPrice(w http.ResponseWriter, r *http.Request) {
 
 			// Check if data is stale
 			dataAge := time.Since(time.Unix(record.Timestamp, 0))
-			if dataAge > h.maxDataAge {
+			if maxDataAge > 0 && dataAge > maxDataAge {
 				needsRefresh = true
 			}
 		}
 	} else {
-		// Cache hit - check if data is stale for a cold tier asset
-		// We don't need to check for hot/medium tier assets as they're auto-refreshed
+		// Cache hit - check if data is older than the tier allows
+		// Frequently refreshed tiers normally stay well within their limit
 		h.metrics.RecordCacheHit()
-		tier := h.refresher.GetAssetTier(symbolLower)
-		if tier == refresher.ColdTier {
+		if maxDataAge > 0 {
 			dataAge := time.Since(time.Unix(priceData.Timestamp, 0))
-			if dataAge > h.maxDataAge {
+			if dataAge > maxDataAge {
 				needsRefresh = true
 			}
 		}
 	}
 
+	// Don't present a price as current once its refreshes have been failing
+	// for too long. Health is tracked per replica, so a recent price written
+	// by the leader, a shard owner or a manual refresh is still served
+	if health == refresher.HealthUnavailable && !withinMaxAge(priceData, maxDataAge) {
+		return nil, h.unavailableError(symbolLower)
+	}
+
+	// An asset in backoff would most likely fail again; serve what we have
+	// rather than adding load on top of the retries
+	if needsRefresh && priceData != nil && health != refresher.HealthHealthy {
+		needsRefresh = false
+	}
+
+	// In stale-while-revalidate mode, stale data within the hard limit is
+	// served right away while a background refresh catches up
+	if needsRefresh && priceData != nil && h.staleWhileRevalidate {
+		dataAge := time.Since(time.Unix(priceData.Timestamp, 0))
+		switch {
+		case h.staleLimit <= 0 || dataAge <= h.staleLimit:
+			h.revalidate(symbolLower)
+			needsRefresh = false
+		case h.rejectBeyondLimit:
+			h.revalidate(symbolLower)
+			apiErr := newAPIError(http.StatusServiceUnavailable, codePriceTooStale, "Asset data is too stale, refresh in progress")
+			apiErr.RetryAfter = 1
+			return nil, apiErr
+		}
+	}
+
 	// If we need fresh data, trigger a refresh
 	if needsRefresh {
 		// For cold tier assets or missing data, force an immediate refresh
-		err := h.refresher.ForceRefresh(symbolLower)
+		// The refreshed price comes straight back so this works without the cache
+		fresh, err := h.refresher.ForceRefreshPrice(symbolLower)
 		if err != nil {
 			log.Printf("Failed to force refresh for %s: %v", symbolLower, err)
 			if priceData == nil {
-				// If we have no data at all, return an error
-				respondWithError(w, http.StatusNotFound, "Asset data not available")
-				return
+				// An exhausted budget is local to this replica and says nothing
+				// about the asset, so it must not become a shared negative entry
+				if errors.Is(err, fetcher.ErrRateLimited) {
+					return nil, rateLimitedError()
+				}
+				// If we have no data at all, remember the failure and return an error
+				if h.negativeTTL > 0 {
+					return nil, negativeEntryError(symbolLower, h.markUnavailable(symbolLower, err))
+				}
+				return nil, newAPIError(http.StatusNotFound, codePriceNotAvailable, "Asset data not available")
 			}
 			// If we have stale data, continue with it
 		} else {
-			// Refresh succeeded, get fresh data from cache
-			priceData, err = h.cache.Get(symbolLower)
-			if err != nil || priceData == nil {
+			// A refresh skipped because one just finished leaves the price in the cache
+			if fresh == nil {
+				fresh, err = h.cache.Get(symbolLower)
+			}
+			if err != nil || fresh == nil {
 				log.Printf("Failed to get fresh data for %s after refresh: %v", symbolLower, err)
 				// Fall back to previous data if available
 				if priceData == nil {
-					respondWithError(w, http.StatusNotFound, "Asset data not available")
-					return
+					return nil, newAPIError(http.StatusNotFound, codePriceNotAvailable, "Asset data not available")
 				}
+			} else {
+				priceData = fresh
 			}
 		}
 	}
@@ -200,16 +307,25 @@ func (h *Handler) GetPrice(w http.ResponseWriter, r *http.Request) {
 	h.metrics.RecordAssetAccess(symbolLower, tierString)
 
 	priceResponse := priceData.ToResponseWithTier(tierString)
-	respondWithJSON(w, http.StatusOK, priceResponse)
+	if health != refresher.HealthHealthy && health != refresher.HealthUnavailable {
+		priceResponse.Health = string(health)
+	}
+	// Whatever path we took, tell the client if the price is older than its tier allows
+	if maxDataAge > 0 && time.Since(time.Unix(priceData.Timestamp, 0)) > maxDataAge {
+		priceResponse.Stale = true
+		h.metrics.RecordStaleResponse(tierString)
+	}
+	return &priceResponse, nil
 }
 
 func (h *Handler) WarmupCache() {
 	log.Println("Starting cache warmup...")
 
-	// Get all hot assets from the refresher
+	// Get all assets in the hottest tier from the refresher
+	hottest := h.refresher.Tiers().Hottest().Name
 	hotAssets := []string{}
 	for asset, tier := range h.refresher.GetAllAssetTiers() {
-		if tier == refresher.HotTier {
+		if tier == hottest {
 			hotAssets = append(hotAssets, asset)
 		}
 	}
@@ -221,27 +337,36 @@ func (h *Handler) WarmupCache() {
 
 	log.Printf("Warming up cache with %d hot assets", len(hotAssets))
 
-	// Batch get hot assets from storage
-	records, err := h.storage.BatchGet(hotAssets)
-	if err != nil {
-		log.Printf("Cache warmup failed: %v", err)
-		return
-	}
-
-	// Warm up the cache with the fetched records
-	for asset, record := range records {
-		priceData := &types.PriceData{
-			Asset:     record.Asset,
-			Price:     record.Price,
-			Timestamp: record.Timestamp,
+	// Batch get hot assets from storage, at most one BatchGetItem's worth at a time
+	warmed := 0
+	for start := 0; start < len(hotAssets); start += maxBatchAssets {
+		end := start + maxBatchAssets
+		if end > len(hotAssets) {
+			end = len(hotAssets)
+		}
+		records, err := h.storage.BatchGet(hotAssets[start:end])
+		if err != nil {
+			log.Printf("Cache warmup failed: %v", err)
+			return
 		}
 
-		if err := h.cache.Set(asset, priceData, "hot"); err != nil {
-			log.Printf("Failed to warm up cache for %s: %v", asset, err)
+		// Warm up the cache with the fetched records in one pipelined write
+		entries := make([]cache.Entry, 0, len(records))
+		for asset, record := range records {
+			entries = append(entries, cache.Entry{
+				Key:  asset,
+				Data: recordToPriceData(record),
+				Tier: hottest,
+			})
+		}
+		if err := h.cache.SetMany(entries); err != nil {
+			log.Printf("Failed to warm up cache: %v", err)
+			continue
 		}
+		warmed += len(entries)
 	}
 
-	log.Printf("Cache warmed up with %d hot assets", len(records))
+	log.Printf("Cache warmed up with %d hot assets", warmed)
 }
 
 // RefreshPrice handles POST /refresh/{asset}
@@ -261,53 +386,223 @@ func (h *Handler) RefreshPrice(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	symbol := vars["asset"]
 	if symbol == "" {
-		respondWithError(w, http.StatusBadRequest, "Asset symbol is required")
+		respondWithError(&recorder, http.StatusBadRequest, codeInvalidRequest, "Asset symbol is required")
 		return
 	}
 
 	// Convert to lowercase for case-insensitive comparison
-	symbolLower := strings.ToLower(symbol)
-
-	// Check if asset exists in CSV
-	if !h.supportedAssets[symbolLower] {
-		respondWithError(w, http.StatusNotFound, "Asset not found")
+	if apiErr := h.refreshPrice(strings.ToLower(symbol)); apiErr != nil {
+		respondWithAPIError(&recorder, apiErr)
 		return
 	}
 
-	// Check if asset is supported
-	tier := h.refresher.GetAssetTier(symbolLower)
-	var tierString string
-	switch tier {
-	case refresher.HotTier:
-		tierString = "hot"
-	case refresher.MediumTier:
-		tierString = "medium"
-	case refresher.ColdTier:
-		tierString = "cold"
+	respondWithJSON(&recorder, http.StatusOK, map[string]string{
+		"message": "Price for " + symbol + " refreshed",
+	})
+}
+
+// refreshPrice forces a refresh of an asset for POST /refresh/{asset} and
+// the gRPC RefreshPrice
+func (h *Handler) refreshPrice(symbolLower string) *apiError {
+	// Check if asset exists in CSV
+	if !h.symbols.IsSupported(symbolLower) {
+		return h.unsupportedAssetError(symbolLower)
 	}
 
+	tierString := h.refresher.GetAssetTier(symbolLower).Name
+
 	// Force a refresh through the refresher service
 	err := h.refresher.ForceRefresh(symbolLower)
 	if err != nil {
-		h.metrics.RecordRefreshError(tierString)
 		log.Printf("Failed to refresh price for %s: %v", symbolLower, err)
-		respondWithError(&recorder, http.StatusInternalServerError, "Failed to refresh price")
-		return
+		if errors.Is(err, fetcher.ErrRateLimited) {
+			return rateLimitedError()
+		}
+		h.metrics.RecordRefreshError(tierString)
+		return newAPIError(http.StatusInternalServerError, codeInternal, "Failed to refresh price")
 	}
 
 	// Update cache and storage
 	h.metrics.RecordRefresh(tierString, "manual")
+	return nil
+}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Price for " + symbol + " refreshed",
+// unsupportedAssetError is the error of every endpoint for an asset that is
+// not in symbols.csv: 410 if it was delisted or disabled, 404 otherwise
+func (h *Handler) unsupportedAssetError(asset string) *apiError {
+	if apiErr := h.delistedError(asset); apiErr != nil {
+		return apiErr
+	}
+	apiErr := newAPIError(http.StatusNotFound, codeAssetNotFound, "Asset not found")
+	apiErr.Details["asset"] = asset
+	return apiErr
+}
+
+// delistedError returns the 410 Gone error of an asset removed from or
+// disabled in symbols.csv, or nil if the asset was never listed
+func (h *Handler) delistedError(asset string) *apiError {
+	if s, ok := h.symbols.Get(asset); ok && !s.Enabled {
+		apiErr := newAPIError(http.StatusGone, codeAssetDisabled, "Asset is disabled")
+		apiErr.Details["asset"] = asset
+		return apiErr
+	}
+
+	delistedAt, ok := h.symbols.DelistedAt(asset)
+	if !ok {
+		return nil
+	}
+	apiErr := newAPIError(http.StatusGone, codeAssetDelisted, "Asset has been delisted")
+	apiErr.Details["asset"] = asset
+	apiErr.Details["delisted_at"] = types.FormatTimestamp(delistedAt.Unix())
+	return apiErr
+}
+
+// revalidate refreshes an asset in the background; ForceRefresh coalesces
+// it with other refreshes of the same asset
+func (h *Handler) revalidate(asset string) {
+	err := h.pool.Submit(func() {
+		if err := h.refresher.ForceRefresh(asset); err != nil {
+			log.Printf("Background refresh failed for %s: %v", asset, err)
+		}
 	})
+	if err != nil {
+		log.Printf("Skipped background refresh for %s: %v", asset, err)
+	}
 }
 
-// respondWithError sends an error response with the specified status code and message
-func respondWithError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"msg": message})
+// AddReadinessCheck registers a dependency check for GET /ready
+// A failing critical check makes the replica not ready; a failing optional
+// one (such as the cache) only reports it as degraded
+func (h *Handler) AddReadinessCheck(name string, critical bool, check func() error) {
+	h.readinessChecks = append(h.readinessChecks, readinessCheck{name: name, critical: critical, check: check})
+}
+
+// Ready handles GET /ready
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	overall, checks := h.readiness()
+	status := http.StatusOK
+	if overall == "not_ready" {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, map[string]interface{}{
+		"status": overall,
+		"checks": checks,
+	})
+}
+
+// IsReady reports whether every critical readiness check passes, for
+// health reporting outside GET /ready such as the gRPC health service
+func (h *Handler) IsReady() bool {
+	overall, _ := h.readiness()
+	return overall != "not_ready"
+}
+
+// readiness runs the readiness checks, returning the overall status
+// (ready, degraded or not_ready) and the result of each check
+func (h *Handler) readiness() (string, map[string]string) {
+	overall := "ready"
+	checks := make(map[string]string, len(h.readinessChecks))
+	for _, c := range h.readinessChecks {
+		if err := c.check(); err != nil {
+			checks[c.name] = err.Error()
+			if c.critical {
+				overall = "not_ready"
+			} else if overall == "ready" {
+				overall = "degraded"
+			}
+			continue
+		}
+		checks[c.name] = "ok"
+	}
+	return overall, checks
+}
+
+// rateLimitedError is the 429 for a refresh this replica has no exchange
+// budget for; budgets refill continuously, so a second is a fair wait
+func rateLimitedError() *apiError {
+	apiErr := newAPIError(http.StatusTooManyRequests, codeRateLimited, "Exchange request budget exhausted, try again later")
+	apiErr.RetryAfter = 1
+	return apiErr
+}
+
+// withinMaxAge reports whether there is a price no older than maxDataAge
+// (0 means any age will do)
+func withinMaxAge(priceData *types.PriceData, maxDataAge time.Duration) bool {
+	if priceData == nil {
+		return false
+	}
+	return maxDataAge <= 0 || time.Since(time.Unix(priceData.Timestamp, 0)) <= maxDataAge
+}
+
+// unavailableError is the 503 for an asset whose refreshes have been
+// failing for too long, telling the client when the next attempt is due
+func (h *Handler) unavailableError(asset string) *apiError {
+	apiErr := newAPIError(http.StatusServiceUnavailable, codeAssetUnavailable, "Asset price is temporarily unavailable")
+	apiErr.Details["asset"] = asset
+	apiErr.Details["health"] = string(refresher.HealthUnavailable)
+	if status, err := h.refresher.GetAssetStatus(asset); err == nil {
+		if !status.LastRefresh.IsZero() {
+			apiErr.Details["last_refresh"] = types.FormatTimestamp(status.LastRefresh.Unix())
+		}
+		if wait := time.Until(status.NextRefresh); wait > 0 {
+			apiErr.RetryAfter = int(wait.Seconds()) + 1
+		}
+	}
+	return apiErr
+}
+
+// markUnavailable stores a negative cache entry for an asset that could not
+// be fetched; the next successful refresh overwrites the cached price and
+// clears it
+func (h *Handler) markUnavailable(asset string, cause error) cache.Unavailable {
+	now := time.Now()
+	u := cache.Unavailable{
+		Since:  now,
+		Reason: cause.Error(),
+		Until:  now.Add(h.negativeTTL),
+	}
+	if status, err := h.refresher.GetAssetStatus(asset); err == nil && !status.FailingSince.IsZero() {
+		u.Since = status.FailingSince
+	}
+	if err := h.cache.SetUnavailable(asset, u, h.negativeTTL); err != nil {
+		log.Printf("Failed to store negative cache entry for %s: %v", asset, err)
+	} else {
+		h.metrics.RecordNegativeCache("store")
+	}
+	return u
+}
+
+// negativeError returns a 503 if the asset has a negative cache entry, or
+// nil if negative caching is off or there is no entry
+func (h *Handler) negativeError(asset string) *apiError {
+	if h.negativeTTL <= 0 {
+		return nil
+	}
+	u, err := h.cache.GetUnavailable(asset)
+	if err != nil {
+		log.Printf("Failed to get negative cache entry for %s: %v", asset, err)
+		return nil
+	}
+	if u == nil {
+		return nil
+	}
+	h.metrics.RecordNegativeCache("hit")
+	return negativeEntryError(asset, *u)
+}
+
+// negativeEntryError is the 503 for an asset no price could be obtained for
+func negativeEntryError(asset string, u cache.Unavailable) *apiError {
+	retryAfter := int(time.Until(u.Until).Seconds()) + 1
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	apiErr := newAPIError(http.StatusServiceUnavailable, codePriceNotAvailable, "Asset data not available")
+	apiErr.RetryAfter = retryAfter
+	apiErr.Details["asset"] = asset
+	apiErr.Details["unavailable_since"] = types.FormatTimestamp(u.Since.Unix())
+	apiErr.Details["reason"] = u.Reason
+	apiErr.Details["retry_after"] = retryAfter
+	return apiErr
 }
 
 // respondWithJSON sends a JSON response with the specified status code and payload
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// APIVersionPrefix is the path prefix of the current API version
+const APIVersionPrefix = "/v1"
+
+const (
+	requestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+// RequestID tags every response with an X-Request-ID, reusing a sane one sent
+// by the client or a proxy so errors can be traced across services
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validRequestID accepts short IDs that are safe to echo in headers and logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit hex ID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Deprecated marks the unversioned aliases of versioned routes: responses
+// carry a Deprecation header and a Link to the versioned path, and each use
+// is counted so the aliases can be removed once clients have moved
+func (h *Handler) Deprecated() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+APIVersionPrefix+r.URL.EscapedPath()+`>; rel="successor-version"`)
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					h.metrics.RecordDeprecatedRequest(template)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"real-time-price-aggregator/internal/cache"
+	"real-time-price-aggregator/internal/refresher"
+	"real-time-price-aggregator/internal/storage"
+	"real-time-price-aggregator/internal/types"
+)
+
+// maxBatchAssets is the most assets read from storage in one call, matching
+// DynamoDB's BatchGetItem limit, and the default batch request size
+const maxBatchAssets = 100
+
+// Per-asset statuses of a batch price response
+const (
+	batchStatusOK          = "ok"
+	batchStatusStale       = "stale"       // older than its tier's max_data_age
+	batchStatusUnsupported = "unsupported" // not in symbols.csv
+	batchStatusUnavailable = "unavailable" // no data yet, or refreshes failing for too long
+)
+
+// batchPriceRequest is the body of POST /prices/batch
+type batchPriceRequest struct {
+	Assets []string `json:"assets"`
+}
+
+// batchPriceEntry is the result for one requested asset; the price fields
+// are only present for ok and stale entries
+type batchPriceEntry struct {
+	Asset  string `json:"asset"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	*types.PriceDataResponse
+}
+
+// batchPriceResponse is the body of GET /prices and POST /prices/batch,
+// with entries in the order the assets were requested
+type batchPriceResponse struct {
+	Prices []batchPriceEntry `json:"prices"`
+}
+
+// recordToPriceData converts a storage record to cache format
+func recordToPriceData(record *storage.PriceRecord) *types.PriceData {
+	return &types.PriceData{
+		Asset:     record.Asset,
+		Price:     record.Price,
+		Timestamp: record.Timestamp,
+	}
+}
+
+// parseAssetList splits a comma-separated asset list, lowercasing and
+// dropping empty and duplicate entries
+func parseAssetList(value string) []string {
+	return normalizeAssets(strings.Split(value, ","))
+}
+
+// normalizeAssets lowercases asset symbols, dropping empty and duplicate entries
+func normalizeAssets(symbols []string) []string {
+	seen := make(map[string]bool)
+	assets := []string{}
+	for _, symbol := range symbols {
+		asset := strings.ToLower(strings.TrimSpace(symbol))
+		if asset == "" || seen[asset] {
+			continue
+		}
+		seen[asset] = true
+		assets = append(assets, asset)
+	}
+	return assets
+}
+
+// SetMaxBatchSize sets the most assets a batch price request may ask for
+func (h *Handler) SetMaxBatchSize(n int) {
+	h.maxBatchSize = n
+}
+
+// GetPrices handles GET /prices?assets=a,b,c
+func (h *Handler) GetPrices(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	recorder := statusRecorder{w, http.StatusOK}
+	defer func() {
+		h.metrics.RecordAPIRequest("/prices?assets", recorder.status)
+		h.metrics.ObserveAPIRequestDuration("/prices?assets", time.Since(startTime))
+	}()
+
+	h.respondWithBatch(&recorder, parseAssetList(r.URL.Query().Get("assets")))
+}
+
+// GetPricesBatch handles POST /prices/batch with a body of {"assets": [...]},
+// for asset lists too long for a query string
+func (h *Handler) GetPricesBatch(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	recorder := statusRecorder{w, http.StatusOK}
+	defer func() {
+		h.metrics.RecordAPIRequest("/prices/batch", recorder.status)
+		h.metrics.ObserveAPIRequestDuration("/prices/batch", time.Since(startTime))
+	}()
+
+	var req batchPriceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		respondWithError(&recorder, http.StatusBadRequest, codeInvalidRequest, "Request body must be {\"assets\": [\"<symbol>\", ...]}")
+		return
+	}
+
+	h.respondWithBatch(&recorder, normalizeAssets(req.Assets))
+}
+
+// respondWithBatch validates the requested assets and writes their prices
+func (h *Handler) respondWithBatch(w http.ResponseWriter, assets []string) {
+	if len(assets) == 0 {
+		respondWithError(w, http.StatusBadRequest, codeInvalidRequest, "At least one asset is required")
+		return
+	}
+	if h.maxBatchSize > 0 && len(assets) > h.maxBatchSize {
+		respondWithError(w, http.StatusBadRequest, codeTooManyAssets, "Too many assets requested")
+		return
+	}
+
+	entries, err := h.batchPrices(assets)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, codeInternal, "Internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, batchPriceResponse{Prices: entries})
+}
+
+// batchPrices looks up several assets with one cache round-trip; assets
+// missing from the cache are read from storage in batches and written back
+// to the cache. Unlike GET /prices/{asset} it never forces refreshes
+func (h *Handler) batchPrices(requested []string) ([]batchPriceEntry, error) {
+	assets := make([]string, 0, len(requested))
+	for _, asset := range requested {
+		if h.symbols.IsSupported(asset) {
+			assets = append(assets, asset)
+		}
+	}
+
+	prices, err := h.cache.GetMany(assets)
+	if err != nil {
+		// Redis may be down; read everything from storage instead
+		log.Printf("Failed to get prices from cache: %v", err)
+		prices = make(map[string]*types.PriceData, len(assets))
+	}
+
+	// Fill cache misses from storage
+	misses := []string{}
+	for _, asset := range assets {
+		if prices[asset] == nil {
+			misses = append(misses, asset)
+		}
+	}
+	for start := 0; start < len(misses); start += maxBatchAssets {
+		end := start + maxBatchAssets
+		if end > len(misses) {
+			end = len(misses)
+		}
+		records, err := h.storage.BatchGet(misses[start:end])
+		if err != nil {
+			log.Printf("Failed to get prices from storage: %v", err)
+			return nil, err
+		}
+		cacheEntries := make([]cache.Entry, 0, len(records))
+		for asset, record := range records {
+			prices[asset] = recordToPriceData(record)
+			cacheEntries = append(cacheEntries, cache.Entry{
+				Key:  asset,
+				Data: prices[asset],
+				Tier: h.refresher.GetAssetTier(asset).Name,
+			})
+		}
+		if err := h.cache.SetMany(cacheEntries); err != nil {
+			log.Printf("Failed to update cache from storage: %v", err)
+		}
+	}
+
+	entries := make([]batchPriceEntry, 0, len(requested))
+	for _, asset := range requested {
+		entries = append(entries, h.batchEntry(asset, prices[asset]))
+	}
+	return entries, nil
+}
+
+// batchEntry builds the result for one asset of a batch
+func (h *Handler) batchEntry(asset string, priceData *types.PriceData) batchPriceEntry {
+	if !h.symbols.IsSupported(asset) {
+		return batchPriceEntry{Asset: asset, Status: batchStatusUnsupported}
+	}
+
+	// Health is tracked per replica; a recent price from elsewhere is still served
+	tier := h.refresher.GetAssetTier(asset)
+	health := h.refresher.GetAssetHealth(asset)
+	if health == refresher.HealthUnavailable && !withinMaxAge(priceData, tier.MaxDataAge.Duration) {
+		return batchPriceEntry{Asset: asset, Status: batchStatusUnavailable, Reason: "refreshes have been failing for too long"}
+	}
+	if priceData == nil {
+		return batchPriceEntry{Asset: asset, Status: batchStatusUnavailable, Reason: "no data yet"}
+	}
+
+	h.refresher.RecordAccess(asset)
+	h.metrics.RecordAssetAccess(asset, tier.Name)
+
+	priceResponse := priceData.ToResponseWithTier(tier.Name)
+	if health != refresher.HealthHealthy && health != refresher.HealthUnavailable {
+		priceResponse.Health = string(health)
+	}
+	entry := batchPriceEntry{Asset: asset, Status: batchStatusOK, PriceDataResponse: &priceResponse}
+	maxDataAge := tier.MaxDataAge.Duration
+	if maxDataAge > 0 && time.Since(time.Unix(priceData.Timestamp, 0)) > maxDataAge {
+		priceResponse.Stale = true
+		entry.Status = batchStatusStale
+		h.metrics.RecordStaleResponse(tier.Name)
+	}
+	return entry
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"real-time-price-aggregator/internal/events"
+)
+
+// SetBroker enables the live price stream, with a comment line sent every
+// heartbeat (0 disables them) so proxies don't close idle connections
+func (h *Handler) SetBroker(b *events.Broker, heartbeat time.Duration) {
+	h.broker = b
+	h.heartbeat = heartbeat
+}
+
+// subscribeAssets parses and validates the assets query parameter of a
+// stream request; no assets means all of them. It returns false after
+// answering with an error
+func (h *Handler) subscribeAssets(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	assets := parseAssetList(r.URL.Query().Get("assets"))
+	if h.maxBatchSize > 0 && len(assets) > h.maxBatchSize {
+		respondWithError(w, http.StatusBadRequest, codeTooManyAssets, "Too many assets requested")
+		return nil, false
+	}
+	for _, asset := range assets {
+		if !h.symbols.IsSupported(asset) {
+			respondWithAPIError(w, h.unsupportedAssetError(asset))
+			return nil, false
+		}
+	}
+	return assets, true
+}
+
+// StreamPrices handles GET /stream/prices?assets=a,b,c with Server-Sent Events
+// Every refreshed price of a requested asset is sent as a "price" event whose
+// id can be passed back in Last-Event-ID to resume after a reconnect, on any
+// replica. If that isn't possible a "reset" event comes first, telling the
+// client it missed updates
+func (h *Handler) StreamPrices(w http.ResponseWriter, r *http.Request) {
+	recorder := statusRecorder{w, http.StatusOK}
+	defer func() {
+		h.metrics.RecordAPIRequest("/stream/prices", recorder.status)
+	}()
+
+	if h.broker == nil {
+		respondWithError(&recorder, http.StatusServiceUnavailable, codeStreamingDisabled, "Price streaming is not enabled")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(&recorder, http.StatusInternalServerError, codeInternal, "Streaming is not supported")
+		return
+	}
+	assets, ok := h.subscribeAssets(&recorder, r)
+	if !ok {
+		return
+	}
+
+	// EventSource sends the id of the last event it saw when reconnecting
+	sub, resumed := h.broker.Subscribe(assets, r.Header.Get("Last-Event-ID"))
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	recorder.WriteHeader(http.StatusOK)
+	if !resumed {
+		if _, err := fmt.Fprint(w, "event: reset\ndata: {}\n\n"); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-sub.Ready():
+			for _, event := range sub.Take() {
+				if err := h.writeEvent(w, event); err != nil {
+					return
+				}
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one price update in SSE format
+func (h *Handler) writeEvent(w http.ResponseWriter, event events.Event) error {
+	payload, err := json.Marshal(event.Data.ToResponseWithTier(h.refresher.GetAssetTier(event.Data.Asset).Name))
+	if err != nil {
+		return err
+	}
+	if event.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: price\ndata: %s\n\n", payload)
+	return err
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"real-time-price-aggregator/internal/events"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// Limits of a WebSocket connection
+const (
+	wsMaxMessageSize = 64 << 10
+	wsWriteTimeout   = 10 * time.Second
+	wsMinThrottle    = 50 * time.Millisecond
+)
+
+// wsRequest is a message from a WebSocket client:
+//
+//	{"action": "subscribe", "assets": ["btcusdt"], "throttle_ms": 250}
+//	{"action": "unsubscribe", "assets": ["btcusdt"]}
+//
+// throttle_ms, if given, limits every asset of the connection to one update
+// per interval (0 turns throttling off)
+type wsRequest struct {
+	Action     string   `json:"action"`
+	Assets     []string `json:"assets"`
+	ThrottleMS *int     `json:"throttle_ms,omitempty"`
+}
+
+// wsMessage is a message to a WebSocket client; Type is "subscribed",
+// "snapshot", "update" or "error"
+type wsMessage struct {
+	Type   string                   `json:"type"`
+	Assets []string                 `json:"assets,omitempty"` // subscribed: the current subscription
+	Prices []batchPriceEntry        `json:"prices,omitempty"` // snapshot: current prices of newly subscribed assets
+	ID     string                   `json:"id,omitempty"`     // update: event id, as in GET /stream/prices
+	Price  *types.PriceDataResponse `json:"price,omitempty"`  // update: the new price
+	Error  *errorBody               `json:"error,omitempty"`  // error: what went wrong, as in HTTP error responses
+}
+
+// SetAllowedOrigins sets the origins browsers may open WebSocket connections
+// from; "*" allows any. Without any only same-origin connections are accepted
+func (h *Handler) SetAllowedOrigins(origins []string) {
+	h.upgrader.CheckOrigin = nil
+	if len(origins) == 0 {
+		return
+	}
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	h.upgrader.CheckOrigin = func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || allowed["*"] || allowed[origin]
+	}
+}
+
+// wsClient is the state of one WebSocket connection, owned by its write loop
+type wsClient struct {
+	conn      *websocket.Conn
+	requestID string
+	sub       *events.Subscription // nil until the first subscribe
+	throttle  time.Duration
+	lastSent  map[string]time.Time
+	held      map[string]events.Event // updates waiting for their asset's throttle interval
+}
+
+// PriceSocket handles GET /ws/prices, upgrading to a WebSocket on which the
+// client subscribes to assets, receives a snapshot of their current prices
+// and then every update, optionally throttled per asset
+func (h *Handler) PriceSocket(w http.ResponseWriter, r *http.Request) {
+	recorder := statusRecorder{w, http.StatusSwitchingProtocols}
+	defer func() {
+		h.metrics.RecordAPIRequest("/ws/prices", recorder.status)
+	}()
+
+	if h.broker == nil {
+		respondWithError(&recorder, http.StatusServiceUnavailable, codeStreamingDisabled, "Price streaming is not enabled")
+		return
+	}
+	// Upgrade writes its own error response and only sends the headers given
+	// here with a successful one
+	requestID := w.Header().Get(requestIDHeader)
+	var responseHeader http.Header
+	if requestID != "" {
+		responseHeader = http.Header{requestIDHeader: {requestID}}
+	}
+	conn, err := h.upgrader.Upgrade(w, r, responseHeader)
+	if err != nil {
+		recorder.status = http.StatusBadRequest
+		return
+	}
+	defer conn.Close()
+
+	client := &wsClient{
+		conn:      conn,
+		requestID: requestID,
+		lastSent:  make(map[string]time.Time),
+		held:      make(map[string]events.Event),
+	}
+	defer func() {
+		if client.sub != nil {
+			h.broker.Unsubscribe(client.sub)
+		}
+	}()
+
+	requests := make(chan wsRequest)
+	readDone := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.readSocket(conn, requests, readDone, stop)
+
+	var ping <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	// release fires when a throttled update is due
+	release := time.NewTimer(time.Hour)
+	release.Stop()
+	defer release.Stop()
+
+	for {
+		var ready <-chan struct{}
+		if client.sub != nil {
+			ready = client.sub.Ready()
+		}
+
+		var err error
+		select {
+		case <-readDone:
+			return
+		case req := <-requests:
+			err = h.handleSocketRequest(client, req)
+		case <-ready:
+			err = h.sendUpdates(client, client.sub.Take(), release)
+		case <-release.C:
+			err = h.sendUpdates(client, nil, release)
+		case <-ping:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readSocket decodes client messages until the connection fails; a message
+// that isn't valid JSON is passed on with no action so it gets an error reply
+// Pongs to our pings keep the read deadline moving
+func (h *Handler) readSocket(conn *websocket.Conn, requests chan<- wsRequest, done chan<- struct{}, stop <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	if h.heartbeat > 0 {
+		conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+		})
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("WebSocket connection failed: %v", err)
+			}
+			return
+		}
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			req = wsRequest{}
+		}
+		select {
+		case requests <- req:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// handleSocketRequest applies a subscribe or unsubscribe message
+func (h *Handler) handleSocketRequest(client *wsClient, req wsRequest) error {
+	assets := normalizeAssets(req.Assets)
+	switch req.Action {
+	case "subscribe", "unsubscribe":
+	case "":
+		return h.socketError(client, codeInvalidRequest, `Message must be {"action": "subscribe" or "unsubscribe", "assets": [...]}`)
+	default:
+		return h.socketError(client, codeInvalidRequest, "Unknown action: "+req.Action)
+	}
+	if req.ThrottleMS != nil {
+		throttle := time.Duration(*req.ThrottleMS) * time.Millisecond
+		if throttle > 0 && throttle < wsMinThrottle {
+			throttle = wsMinThrottle
+		}
+		client.throttle = throttle
+	}
+
+	if req.Action == "unsubscribe" {
+		if client.sub != nil {
+			client.sub.Remove(assets...)
+		}
+		for _, asset := range assets {
+			delete(client.held, asset)
+		}
+		return h.writeSocket(client, wsMessage{Type: "subscribed", Assets: h.socketAssets(client)})
+	}
+
+	if len(assets) == 0 && req.ThrottleMS == nil {
+		return h.socketError(client, codeInvalidRequest, "At least one asset is required")
+	}
+	for _, asset := range assets {
+		if !h.symbols.IsSupported(asset) {
+			return h.socketAPIError(client, h.unsupportedAssetError(asset))
+		}
+	}
+	if h.maxBatchSize > 0 && len(h.socketAssets(client))+len(assets) > h.maxBatchSize {
+		return h.socketError(client, codeTooManyAssets, "Too many assets requested")
+	}
+	if len(assets) == 0 {
+		return nil // only the throttle changed
+	}
+
+	// Subscribe before reading the snapshot so no update falls in between;
+	// an update that raced the snapshot is at worst sent twice
+	if client.sub == nil {
+		client.sub, _ = h.broker.Subscribe(assets, "")
+	} else {
+		client.sub.Add(assets...)
+	}
+	if err := h.writeSocket(client, wsMessage{Type: "subscribed", Assets: h.socketAssets(client)}); err != nil {
+		return err
+	}
+
+	snapshot, err := h.batchPrices(assets)
+	if err != nil {
+		return h.socketError(client, codeInternal, "Failed to read current prices")
+	}
+	now := time.Now()
+	for _, asset := range assets {
+		client.lastSent[asset] = now
+	}
+	return h.writeSocket(client, wsMessage{Type: "snapshot", Prices: snapshot})
+}
+
+// socketAssets returns the assets a connection is subscribed to
+func (h *Handler) socketAssets(client *wsClient) []string {
+	if client.sub == nil {
+		return []string{}
+	}
+	return client.sub.Assets()
+}
+
+// sendUpdates sends new updates whose asset is not throttled and holds the
+// rest, together with held updates that have become due. release is reset
+// to fire when the next held update is due
+func (h *Handler) sendUpdates(client *wsClient, updates []events.Event, release *time.Timer) error {
+	for _, event := range updates {
+		client.held[event.Data.Asset] = event
+	}
+
+	now := time.Now()
+	var next time.Time
+	for asset, event := range client.held {
+		due := client.lastSent[asset].Add(client.throttle)
+		if client.throttle > 0 && now.Before(due) {
+			if next.IsZero() || due.Before(next) {
+				next = due
+			}
+			continue
+		}
+		delete(client.held, asset)
+		client.lastSent[asset] = now
+
+		price := event.Data.ToResponseWithTier(h.refresher.GetAssetTier(asset).Name)
+		if err := h.writeSocket(client, wsMessage{Type: "update", ID: event.ID, Price: &price}); err != nil {
+			return err
+		}
+	}
+
+	if !next.IsZero() {
+		release.Reset(time.Until(next))
+	}
+	return nil
+}
+
+// writeSocket sends a message, giving up on clients that stop reading
+func (h *Handler) writeSocket(client *wsClient, msg wsMessage) error {
+	client.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return client.conn.WriteJSON(msg)
+}
+
+// socketError sends an error message with the connection's request ID
+func (h *Handler) socketError(client *wsClient, code, msg string) error {
+	return h.writeSocket(client, wsMessage{Type: "error", Error: &errorBody{
+		Code:      code,
+		Message:   msg,
+		RequestID: client.requestID,
+	}})
+}
+
+// socketAPIError sends an API error, with its details, as an error message
+func (h *Handler) socketAPIError(client *wsClient, apiErr *apiError) error {
+	return h.writeSocket(client, wsMessage{Type: "error", Error: &errorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: client.requestID,
+		Details:   apiErr.Details,
+	}})
+}
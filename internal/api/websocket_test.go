@@ -0,0 +1,280 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"real-time-price-aggregator/internal/cache"
+	"real-time-price-aggregator/internal/events"
+	"real-time-price-aggregator/internal/fetcher"
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/refresher"
+	"real-time-price-aggregator/internal/storage"
+	"real-time-price-aggregator/internal/symbols"
+	"real-time-price-aggregator/internal/tiers"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/websocket"
+)
+
+// testMetrics is shared by every test: the collectors register globally
+var testMetrics = metrics.NewMetricsService()
+
+// quietPeriod is how long a test waits to be sure no message is coming
+const quietPeriod = 300 * time.Millisecond
+
+// memStorage is an in-memory storage.Storage keeping the latest record per asset
+type memStorage struct {
+	mutex   sync.Mutex
+	records map[string]storage.PriceRecord
+}
+
+func (s *memStorage) Save(record storage.PriceRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records[record.Asset] = record
+	return nil
+}
+
+func (s *memStorage) SaveFenced(record storage.PriceRecord, token int64) (bool, error) {
+	return true, s.Save(record)
+}
+
+func (s *memStorage) Get(asset string) (*storage.PriceRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record, ok := s.records[asset]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *memStorage) BatchGet(assets []string) (map[string]*storage.PriceRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	records := make(map[string]*storage.PriceRecord)
+	for _, asset := range assets {
+		if record, ok := s.records[asset]; ok {
+			records[asset] = &record
+		}
+	}
+	return records, nil
+}
+
+// mockExchange serves random tickers like mocks/mock_server.go
+func mockExchange(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := strings.TrimPrefix(r.URL.Path, "/mock/ticker/")
+		price := 50.0 + rand.Float64()*50.0
+		volume := 1000000.0 + rand.Float64()*9000000.0
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"symbol":"%s","price":%.2f,"volume":%.2f,"timestamp":%d}`, symbol, price, volume, time.Now().Unix())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// socketTest is a PriceSocket served over HTTP with its refresher, which
+// fetches from three mock exchanges and publishes through Redis
+type socketTest struct {
+	refresher *refresher.Refresher
+	url       string
+}
+
+func newSocketTest(t *testing.T, assets ...string) *socketTest {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	endpoints := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		endpoints = append(endpoints, mockExchange(t).URL+"/mock/ticker")
+	}
+	priceFetcher := fetcher.NewFetcher(endpoints, fetcher.RateLimitConfig{}, testMetrics)
+
+	tierConfig := tiers.Default()
+	codec, _ := cache.NewCodec("json")
+	priceCache := cache.NewRedisCache(client, tierConfig, "", codec, testMetrics)
+	priceStorage := &memStorage{records: make(map[string]storage.PriceRecord)}
+
+	list := make([]symbols.Symbol, 0, len(assets))
+	for _, asset := range assets {
+		list = append(list, symbols.Symbol{Symbol: asset, Enabled: true})
+	}
+	publisher := events.NewPublisher(client, "", 1000, testMetrics)
+	priceRefresher := refresher.NewRefresher(priceFetcher, priceCache, priceStorage, tierConfig, assets, testMetrics)
+	priceRefresher.SetPublisher(publisher)
+	priceRefresher.SetMinForceInterval(0)
+
+	broker := events.NewBroker(client, publisher, 100, testMetrics)
+	broker.Start()
+	t.Cleanup(broker.Stop)
+
+	handler := NewHandler(priceFetcher, priceCache, priceStorage, priceRefresher, symbols.NewRegistry(list), nil, testMetrics)
+	handler.SetBroker(broker, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(handler.PriceSocket))
+	t.Cleanup(server.Close)
+
+	return &socketTest{
+		refresher: priceRefresher,
+		url:       "ws" + strings.TrimPrefix(server.URL, "http"),
+	}
+}
+
+// dial opens a WebSocket to the price socket
+func (st *socketTest) dial(t *testing.T) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(st.url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// refresh forces a refresh of an asset from the mock exchanges and returns its price
+func (st *socketTest) refresh(t *testing.T, asset string) float64 {
+	t.Helper()
+	priceData, err := st.refresher.ForceRefreshPrice(asset)
+	if err != nil {
+		t.Fatalf("refresh %s: %v", asset, err)
+	}
+	return priceData.Price
+}
+
+// send writes a client request
+func send(t *testing.T, conn *websocket.Conn, req string) {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatalf("send %s: %v", req, err)
+	}
+}
+
+// receive reads the next message, failing if it isn't of the wanted type
+func receive(t *testing.T, conn *websocket.Conn, wantType string) wsMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("waiting for %s: %v", wantType, err)
+	}
+	if msg.Type != wantType {
+		t.Fatalf("got %s message %+v, want %s", msg.Type, msg, wantType)
+	}
+	return msg
+}
+
+// expectSilence fails if a message arrives within quietPeriod; the
+// connection can't be read from afterwards
+func expectSilence(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(quietPeriod))
+	var msg wsMessage
+	err := conn.ReadJSON(&msg)
+	if err == nil {
+		t.Fatalf("got unexpected %s message %+v", msg.Type, msg)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("read: %v", err)
+	}
+}
+
+// subscribe subscribes to assets and checks the subscribed and snapshot replies
+func subscribe(t *testing.T, conn *websocket.Conn, req string, assets ...string) wsMessage {
+	t.Helper()
+	send(t, conn, req)
+
+	subscribed := receive(t, conn, "subscribed")
+	if strings.Join(subscribed.Assets, ",") != strings.Join(assets, ",") {
+		t.Fatalf("subscribed to %v, want %v", subscribed.Assets, assets)
+	}
+	snapshot := receive(t, conn, "snapshot")
+	if len(snapshot.Prices) != len(assets) {
+		t.Fatalf("snapshot has %d prices, want %d", len(snapshot.Prices), len(assets))
+	}
+	for i, entry := range snapshot.Prices {
+		if entry.Asset != assets[i] {
+			t.Fatalf("snapshot entry %d is %s, want %s", i, entry.Asset, assets[i])
+		}
+	}
+	return snapshot
+}
+
+func TestPriceSocketSubscribeSnapshotUpdate(t *testing.T) {
+	st := newSocketTest(t, "asset1")
+	initial := st.refresh(t, "asset1")
+	conn := st.dial(t)
+
+	snapshot := subscribe(t, conn, `{"action": "subscribe", "assets": ["asset1"]}`, "asset1")
+	if entry := snapshot.Prices[0]; entry.Status != batchStatusOK || entry.PriceDataResponse == nil || entry.Price != initial {
+		t.Fatalf("snapshot entry %+v, want status ok and price %v", entry, initial)
+	}
+
+	price := st.refresh(t, "asset1")
+	update := receive(t, conn, "update")
+	if update.ID == "" {
+		t.Fatal("update has no stream id")
+	}
+	if update.Price == nil || update.Price.Asset != "asset1" || update.Price.Price != price {
+		t.Fatalf("update %+v, want asset1 at %v", update.Price, price)
+	}
+}
+
+func TestPriceSocketThrottle(t *testing.T) {
+	const throttle = 200 * time.Millisecond
+	st := newSocketTest(t, "asset1")
+	conn := st.dial(t)
+
+	subscribe(t, conn, `{"action": "subscribe", "assets": ["asset1"], "throttle_ms": 200}`, "asset1")
+	snapshotAt := time.Now()
+
+	// Refreshes within the interval are held back; only the newest is sent
+	// once the interval since the snapshot has passed
+	var last float64
+	for i := 0; i < 5; i++ {
+		last = st.refresh(t, "asset1")
+	}
+	update := receive(t, conn, "update")
+	if elapsed := time.Since(snapshotAt); elapsed < throttle-10*time.Millisecond {
+		t.Fatalf("update sent %v after the snapshot, want at least %v", elapsed, throttle)
+	}
+	if update.Price == nil || update.Price.Price != last {
+		t.Fatalf("update %+v, want the newest price %v", update.Price, last)
+	}
+	expectSilence(t, conn)
+}
+
+func TestPriceSocketUnsubscribe(t *testing.T) {
+	st := newSocketTest(t, "asset1", "asset2")
+	conn := st.dial(t)
+
+	subscribe(t, conn, `{"action": "subscribe", "assets": ["asset1", "asset2"]}`, "asset1", "asset2")
+
+	send(t, conn, `{"action": "unsubscribe", "assets": ["asset1"]}`)
+	subscribed := receive(t, conn, "subscribed")
+	if strings.Join(subscribed.Assets, ",") != "asset2" {
+		t.Fatalf("subscribed to %v after unsubscribing asset1, want [asset2]", subscribed.Assets)
+	}
+
+	st.refresh(t, "asset1")
+	price := st.refresh(t, "asset2")
+	update := receive(t, conn, "update")
+	if update.Price == nil || update.Price.Asset != "asset2" || update.Price.Price != price {
+		t.Fatalf("update %+v, want asset2 at %v", update.Price, price)
+	}
+	expectSilence(t, conn)
+}
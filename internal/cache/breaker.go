@@ -0,0 +1,123 @@
+// internal/cache/breaker.go
+package cache
+
+import (
+	"context"
+	"time"
+
+	"real-time-price-aggregator/internal/circuitbreaker"
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// BreakerCache guards a cache with a circuit breaker so that a Redis outage
+// makes cache calls fail fast instead of waiting for timeouts on every request
+// Callers treat its errors as misses and fall back to storage
+type BreakerCache struct {
+	inner   Cache
+	client  redis.UniversalClient
+	breaker *circuitbreaker.CircuitBreaker
+	metrics *metrics.MetricsService
+}
+
+// NewBreakerCache wraps inner; client is pinged by Check
+// The circuit opens after 5 consecutive failures and retries after resetTimeout
+func NewBreakerCache(inner Cache, client redis.UniversalClient, resetTimeout time.Duration, m *metrics.MetricsService) *BreakerCache {
+	return &BreakerCache{
+		inner:   inner,
+		client:  client,
+		breaker: circuitbreaker.New("redis", 5, resetTimeout, 2),
+		metrics: m,
+	}
+}
+
+// execute runs fn through the breaker and exports the breaker state
+func (c *BreakerCache) execute(fn func() error) error {
+	err := c.breaker.Execute(fn)
+	c.metrics.RecordCircuitBreakerState("redis", int(c.breaker.GetState()))
+	return err
+}
+
+// Get retrieves price data unless the circuit is open
+func (c *BreakerCache) Get(key string) (*types.PriceData, error) {
+	var data *types.PriceData
+	err := c.execute(func() error {
+		var err error
+		data, err = c.inner.Get(key)
+		return err
+	})
+	return data, err
+}
+
+// Set stores price data unless the circuit is open
+func (c *BreakerCache) Set(key string, data *types.PriceData, tierType string) error {
+	return c.execute(func() error {
+		return c.inner.Set(key, data, tierType)
+	})
+}
+
+// SetFenced stores price data unless the circuit is open; a write rejected
+// for its token is not a failure
+func (c *BreakerCache) SetFenced(key string, data *types.PriceData, tierType string, token int64) (bool, error) {
+	var written bool
+	err := c.execute(func() error {
+		var err error
+		written, err = c.inner.SetFenced(key, data, tierType, token)
+		return err
+	})
+	return written, err
+}
+
+// GetMany retrieves several prices unless the circuit is open
+func (c *BreakerCache) GetMany(keys []string) (map[string]*types.PriceData, error) {
+	var result map[string]*types.PriceData
+	err := c.execute(func() error {
+		var err error
+		result, err = c.inner.GetMany(keys)
+		return err
+	})
+	return result, err
+}
+
+// SetMany stores several prices unless the circuit is open
+func (c *BreakerCache) SetMany(entries []Entry) error {
+	return c.execute(func() error {
+		return c.inner.SetMany(entries)
+	})
+}
+
+// GetUnavailable reads a negative entry unless the circuit is open
+func (c *BreakerCache) GetUnavailable(key string) (*Unavailable, error) {
+	var u *Unavailable
+	err := c.execute(func() error {
+		var err error
+		u, err = c.inner.GetUnavailable(key)
+		return err
+	})
+	return u, err
+}
+
+// SetUnavailable stores a negative entry unless the circuit is open
+func (c *BreakerCache) SetUnavailable(key string, u Unavailable, ttl time.Duration) error {
+	return c.execute(func() error {
+		return c.inner.SetUnavailable(key, u, ttl)
+	})
+}
+
+// Check pings Redis through the breaker, for readiness probes
+// While the circuit is open it fails without touching Redis
+func (c *BreakerCache) Check() error {
+	return c.execute(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return c.client.Ping(ctx).Err()
+	})
+}
+
+// Execute runs fn through the Redis circuit breaker, so other Redis users
+// such as the event publisher fail fast with the cache while it is open
+func (c *BreakerCache) Execute(fn func() error) error {
+	return c.execute(fn)
+}
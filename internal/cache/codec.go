@@ -0,0 +1,134 @@
+// internal/cache/codec.go
+package cache
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"real-time-price-aggregator/internal/types"
+)
+
+// Format markers in the first byte of a cached value
+// JSON values are stored without a marker so replicas that only know JSON can
+// still read them; they always start with '{'
+const (
+	jsonMarker     byte = '{'
+	binaryVersion1 byte = 0x01
+)
+
+// ErrUnknownEncoding is returned for cached values in a format this build can't read
+var ErrUnknownEncoding = errors.New("unknown cache value encoding")
+
+// Codec encodes prices for the cache
+// Every codec decodes every known format, so replicas writing different
+// formats during a rollout can read each other's entries
+type Codec interface {
+	Name() string
+	Encode(data *types.PriceData) ([]byte, error)
+	Decode(value []byte) (*types.PriceData, error)
+}
+
+// NewCodec returns the codec with the given name ("json" or "binary")
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case "", "json":
+		return JSONCodec{}, nil
+	case "binary":
+		return BinaryCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache codec %q", name)
+	}
+}
+
+// JSONCodec writes plain JSON, the original cache format
+type JSONCodec struct{}
+
+// Name returns the codec name
+func (JSONCodec) Name() string { return "json" }
+
+// Encode marshals the price as JSON
+func (JSONCodec) Encode(data *types.PriceData) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+// Decode reads a value in any known format
+func (JSONCodec) Decode(value []byte) (*types.PriceData, error) {
+	return decodeValue(value)
+}
+
+// BinaryCodec writes a compact versioned binary form:
+// version byte, uvarint asset length, asset, float64 price (big endian),
+// varint timestamp
+type BinaryCodec struct{}
+
+// Name returns the codec name
+func (BinaryCodec) Name() string { return "binary" }
+
+// Encode writes the price in binary version 1
+func (BinaryCodec) Encode(data *types.PriceData) ([]byte, error) {
+	if data == nil {
+		return nil, errors.New("cannot encode nil price")
+	}
+	buf := make([]byte, 0, 1+binary.MaxVarintLen64+len(data.Asset)+8+binary.MaxVarintLen64)
+	buf = append(buf, binaryVersion1)
+	buf = binary.AppendUvarint(buf, uint64(len(data.Asset)))
+	buf = append(buf, data.Asset...)
+	buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(data.Price))
+	buf = binary.AppendVarint(buf, data.Timestamp)
+	return buf, nil
+}
+
+// Decode reads a value in any known format
+func (BinaryCodec) Decode(value []byte) (*types.PriceData, error) {
+	return decodeValue(value)
+}
+
+// decodeValue picks the decoder from the value's first byte
+func decodeValue(value []byte) (*types.PriceData, error) {
+	if len(value) == 0 {
+		return nil, ErrUnknownEncoding
+	}
+
+	switch value[0] {
+	case jsonMarker:
+		var priceData types.PriceData
+		if err := json.Unmarshal(value, &priceData); err != nil {
+			return nil, err
+		}
+		return &priceData, nil
+	case binaryVersion1:
+		return decodeBinaryV1(value[1:])
+	default:
+		return nil, fmt.Errorf("%w: version byte 0x%02x", ErrUnknownEncoding, value[0])
+	}
+}
+
+// errTruncated is returned for binary values that end early
+var errTruncated = errors.New("truncated binary cache value")
+
+// decodeBinaryV1 reads the body of a binary version 1 value
+func decodeBinaryV1(body []byte) (*types.PriceData, error) {
+	assetLen, n := binary.Uvarint(body)
+	if n <= 0 || uint64(len(body)-n) < assetLen {
+		return nil, errTruncated
+	}
+	body = body[n:]
+	asset := string(body[:assetLen])
+	body = body[assetLen:]
+
+	if len(body) < 8 {
+		return nil, errTruncated
+	}
+	price := math.Float64frombits(binary.BigEndian.Uint64(body))
+	body = body[8:]
+
+	timestamp, n := binary.Varint(body)
+	if n <= 0 {
+		return nil, errTruncated
+	}
+
+	return &types.PriceData{Asset: asset, Price: price, Timestamp: timestamp}, nil
+}
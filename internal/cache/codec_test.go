@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"real-time-price-aggregator/internal/types"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	prices := []struct {
+		name string
+		data types.PriceData
+	}{
+		{name: "typical", data: types.PriceData{Asset: "btc", Price: 64123.45, Timestamp: 1700000000}},
+		{name: "empty asset", data: types.PriceData{Price: 1, Timestamp: 1}},
+		{name: "long asset", data: types.PriceData{Asset: string(make([]byte, 300)), Price: 0.5, Timestamp: 1}},
+		{name: "zero", data: types.PriceData{Asset: "x"}},
+		{name: "negative timestamp", data: types.PriceData{Asset: "x", Price: 2, Timestamp: -5}},
+		{name: "extreme price", data: types.PriceData{Asset: "x", Price: math.MaxFloat64, Timestamp: math.MaxInt64}},
+	}
+	codecs := []Codec{JSONCodec{}, BinaryCodec{}}
+
+	for _, tt := range prices {
+		for _, writer := range codecs {
+			for _, reader := range codecs {
+				// Every codec must read what every other one wrote
+				t.Run(tt.name+"/"+writer.Name()+" to "+reader.Name(), func(t *testing.T) {
+					value, err := writer.Encode(&tt.data)
+					if err != nil {
+						t.Fatalf("Encode: %v", err)
+					}
+					got, err := reader.Decode(value)
+					if err != nil {
+						t.Fatalf("Decode: %v", err)
+					}
+					if *got != tt.data {
+						t.Errorf("decoded %+v, want %+v", *got, tt.data)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	valid, err := BinaryCodec{}.Encode(&types.PriceData{Asset: "btc", Price: 1, Timestamp: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value []byte
+		want  error // nil for any error
+	}{
+		{name: "empty", value: nil, want: ErrUnknownEncoding},
+		{name: "unknown version", value: []byte{0x02, 0x00}, want: ErrUnknownEncoding},
+		{name: "plain text", value: []byte("42"), want: ErrUnknownEncoding},
+		{name: "version only", value: []byte{binaryVersion1}, want: errTruncated},
+		{name: "asset cut short", value: valid[:3], want: errTruncated},
+		{name: "price cut short", value: valid[:len(valid)-3], want: errTruncated},
+		{name: "no timestamp", value: valid[:len(valid)-1], want: errTruncated},
+		{name: "broken json", value: []byte(`{"asset":`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeValue(tt.value)
+			if err == nil {
+				t.Fatalf("decoded %+v, want an error", got)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCodec(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "json"},
+		{name: "json", want: "json"},
+		{name: "binary", want: "binary"},
+		{name: "msgpack", wantErr: true},
+	}
+	for _, tt := range tests {
+		codec, err := NewCodec(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewCodec(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && codec.Name() != tt.want {
+			t.Errorf("NewCodec(%q) = %s, want %s", tt.name, codec.Name(), tt.want)
+		}
+	}
+}
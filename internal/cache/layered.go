@@ -0,0 +1,246 @@
+// internal/cache/layered.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// InvalidationChannel is the default Redis pub/sub channel on which replicas
+// announce that they wrote a new price for an asset
+const InvalidationChannel = "prices:invalidate"
+
+// invalidation is the message published on the invalidation channel
+type invalidation struct {
+	Key    string `json:"key"`
+	Origin string `json:"origin"`
+}
+
+// LayeredCache keeps recently read prices in process (L1) in front of a
+// shared cache (L2, normally Redis). Writes go to both levels and are
+// announced over Redis pub/sub so other replicas drop their L1 copy; the
+// short L1 TTL bounds staleness if an announcement is missed
+type LayeredCache struct {
+	l1      *lru
+	l2      Cache
+	client  redis.UniversalClient
+	channel string
+	origin  string
+	ttl     time.Duration
+	metrics *metrics.MetricsService
+	mutex   sync.Mutex
+	pubsub  *redis.PubSub
+	done    chan struct{}
+}
+
+// NewLayeredCache creates an L1 cache of size entries with the given TTL over
+// l2. Invalidations are exchanged on channel, and origin identifies this
+// replica so it ignores its own announcements
+func NewLayeredCache(
+	l2 Cache,
+	client redis.UniversalClient,
+	channel string,
+	origin string,
+	size int,
+	ttl time.Duration,
+	m *metrics.MetricsService,
+) *LayeredCache {
+	return &LayeredCache{
+		l1:      newLRU(size),
+		l2:      l2,
+		client:  client,
+		channel: channel,
+		origin:  origin,
+		ttl:     ttl,
+		metrics: m,
+	}
+}
+
+// Get returns the price from L1, falling back to L2 and keeping the result
+func (c *LayeredCache) Get(key string) (*types.PriceData, error) {
+	if data, ok := c.l1.get(key); ok {
+		c.metrics.RecordL1Cache("hit")
+		return data, nil
+	}
+	c.metrics.RecordL1Cache("miss")
+
+	data, err := c.l2.Get(key)
+	if err != nil || data == nil {
+		return data, err
+	}
+	c.store(key, data)
+	return data, nil
+}
+
+// Set writes the price to L2 and L1 and tells other replicas to drop their copy
+func (c *LayeredCache) Set(key string, data *types.PriceData, tierType string) error {
+	if err := c.l2.Set(key, data, tierType); err != nil {
+		c.l1.remove(key)
+		return err
+	}
+	if data == nil {
+		c.l1.remove(key)
+	} else {
+		c.store(key, data)
+	}
+	c.announce(key)
+	return nil
+}
+
+// SetFenced writes the price to L2 if the token allows it, then to L1, and
+// tells other replicas to drop their copy. A rejected write drops the L1
+// copy, since L2 holds a newer writer's price
+func (c *LayeredCache) SetFenced(key string, data *types.PriceData, tierType string, token int64) (bool, error) {
+	written, err := c.l2.SetFenced(key, data, tierType, token)
+	if err != nil || !written {
+		c.l1.remove(key)
+		return written, err
+	}
+	c.store(key, data)
+	c.announce(key)
+	return true, nil
+}
+
+// GetMany serves what it can from L1 and reads the rest from L2 in one call
+func (c *LayeredCache) GetMany(keys []string) (map[string]*types.PriceData, error) {
+	result := make(map[string]*types.PriceData, len(keys))
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if data, ok := c.l1.get(key); ok {
+			c.metrics.RecordL1Cache("hit")
+			result[key] = data
+			continue
+		}
+		c.metrics.RecordL1Cache("miss")
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	fromL2, err := c.l2.GetMany(missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, data := range fromL2 {
+		c.store(key, data)
+		result[key] = data
+	}
+	return result, nil
+}
+
+// SetMany writes the prices to L2 and L1 and announces them together
+func (c *LayeredCache) SetMany(entries []Entry) error {
+	if err := c.l2.SetMany(entries); err != nil {
+		for _, e := range entries {
+			c.l1.remove(e.Key)
+		}
+		return err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Data == nil {
+			c.l1.remove(e.Key)
+		} else {
+			c.store(e.Key, e.Data)
+		}
+		keys = append(keys, e.Key)
+	}
+	c.announce(keys...)
+	return nil
+}
+
+// GetUnavailable reads negative entries from L2 only; they are rare and
+// must disappear everywhere as soon as a price is stored
+func (c *LayeredCache) GetUnavailable(key string) (*Unavailable, error) {
+	return c.l2.GetUnavailable(key)
+}
+
+// SetUnavailable stores a negative entry in L2
+func (c *LayeredCache) SetUnavailable(key string, u Unavailable, ttl time.Duration) error {
+	return c.l2.SetUnavailable(key, u, ttl)
+}
+
+// store puts a price in L1 and counts evictions
+func (c *LayeredCache) store(key string, data *types.PriceData) {
+	if c.l1.put(key, data, c.ttl) {
+		c.metrics.RecordL1Cache("eviction")
+	}
+}
+
+// announce publishes an invalidation for each key in one round-trip
+func (c *LayeredCache) announce(keys ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		msg, err := json.Marshal(invalidation{Key: key, Origin: c.origin})
+		if err != nil {
+			continue
+		}
+		pipe.Publish(ctx, c.channel, msg)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to publish cache invalidation for %v: %v", keys, err)
+	}
+}
+
+// Start subscribes to invalidations from other replicas
+func (c *LayeredCache) Start() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.pubsub != nil {
+		return
+	}
+	c.pubsub = c.client.Subscribe(context.Background(), c.channel)
+	c.done = make(chan struct{})
+	log.Printf("L1 cache enabled (%d entries, TTL %v)", c.l1.capacity, c.ttl)
+
+	go c.listen(c.pubsub, c.done)
+}
+
+// Stop unsubscribes from invalidations
+func (c *LayeredCache) Stop() {
+	c.mutex.Lock()
+	pubsub, done := c.pubsub, c.done
+	c.pubsub = nil
+	c.mutex.Unlock()
+
+	if pubsub == nil {
+		return
+	}
+	pubsub.Close()
+	<-done
+}
+
+// listen drops L1 entries announced by other replicas until the subscription closes
+// go-redis resubscribes after connection errors; anything missed meanwhile
+// expires from L1 within its TTL
+func (c *LayeredCache) listen(pubsub *redis.PubSub, done chan<- struct{}) {
+	defer close(done)
+
+	for msg := range pubsub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			log.Printf("Ignoring malformed cache invalidation %q: %v", msg.Payload, err)
+			continue
+		}
+		if inv.Origin == c.origin {
+			continue
+		}
+		if c.l1.remove(inv.Key) {
+			c.metrics.RecordL1Cache("invalidation")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// internal/cache/lru.go
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"real-time-price-aggregator/internal/types"
+)
+
+// lruEntry is an item in the LRU list
+type lruEntry struct {
+	key       string
+	data      types.PriceData
+	expiresAt time.Time
+}
+
+// lru is a size-bounded, least-recently-used map with per-entry expiry
+type lru struct {
+	mutex    sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+// newLRU creates an LRU holding at most capacity entries
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns a copy of the entry for key if present and not expired
+func (l *lru) get(key string) (*types.PriceData, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.removeElement(elem)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	data := entry.data
+	return &data, true
+}
+
+// put stores a copy of data under key, evicting the least recently used
+// entry when full. It returns true if an entry was evicted
+func (l *lru) put(key string, data *types.PriceData, ttl time.Duration) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.data = *data
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return false
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, data: *data, expiresAt: expiresAt})
+	if l.order.Len() <= l.capacity {
+		return false
+	}
+	l.removeElement(l.order.Back())
+	return true
+}
+
+// remove deletes key, reporting whether it was present
+func (l *lru) remove(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return false
+	}
+	l.removeElement(elem)
+	return true
+}
+
+// removeElement unlinks an element; caller holds the mutex
+func (l *lru) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*lruEntry).key)
+}
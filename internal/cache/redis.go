@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/tiers"
 	"real-time-price-aggregator/internal/types"
 
 	"github.com/go-redis/redis/v8"
@@ -14,22 +16,101 @@ import (
 type Cache interface {
 	Get(key string) (*types.PriceData, error)
 	Set(key string, data *types.PriceData, tierType string) error
+	// SetFenced stores a price like Set unless the key was written with a
+	// newer fencing token than token, and reports whether it stored it
+	SetFenced(key string, data *types.PriceData, tierType string, token int64) (bool, error)
+
+	// GetMany returns the cached prices of several keys; missing keys are left out
+	GetMany(keys []string) (map[string]*types.PriceData, error)
+	// SetMany stores several prices, each with its own tier's TTL
+	SetMany(entries []Entry) error
+
+	// GetUnavailable returns the negative entry of a key, or nil if there is none
+	GetUnavailable(key string) (*Unavailable, error)
+	// SetUnavailable records that no price could be obtained for a key;
+	// storing a price for the key with Set or SetMany clears it
+	SetUnavailable(key string, u Unavailable, ttl time.Duration) error
+}
+
+// Unavailable is a negative cache entry for an asset no price could be obtained for
+type Unavailable struct {
+	Since  time.Time `json:"since"`
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until"` // when the entry expires and fetching is tried again
+}
+
+// unavailablePrefix namespaces negative entries apart from prices
+const unavailablePrefix = "unavailable:"
+
+// fenceSuffix follows the hash-tagged price key in the name of the key
+// holding the newest fencing token that wrote the price
+const fenceSuffix = ":fence"
+
+// fencedSetScript stores a price unless its fence key holds a newer token
+// KEYS: price key, fence key; ARGV: token, value, TTL in ms (0 for none)
+var fencedSetScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[2]) or "0")
+if tonumber(ARGV[1]) < current then
+	return 0
+end
+redis.call("SET", KEYS[2], ARGV[1])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1`)
+
+// Entry is a price to store with SetMany
+type Entry struct {
+	Key  string
+	Data *types.PriceData
+	Tier string
 }
 
 // RedisCache implements the Cache interface using Redis
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
+	tiers  *tiers.Config
+	prefix string // namespace prepended to every key
+	codec  Codec
+	ttl    TTLPolicy
+
+	metrics *metrics.MetricsService
 }
 
 // NewRedisCache creates a new Redis cache instance
-func NewRedisCache(client *redis.Client) *RedisCache {
-	return &RedisCache{client: client}
+// TTLs are derived from the refresh interval of each tier in the config (see
+// TTLPolicy), and keys are stored as prefix+asset so several environments can
+// share one Redis. Values are written with codec and read in any known format
+func NewRedisCache(client redis.UniversalClient, t *tiers.Config, prefix string, codec Codec, m *metrics.MetricsService) *RedisCache {
+	return &RedisCache{
+		client:  client,
+		tiers:   t,
+		prefix:  prefix,
+		codec:   codec,
+		ttl:     DefaultTTLPolicy(),
+		metrics: m,
+	}
 }
 
-// Get retrieves price data from Redis
+// SetTTLPolicy replaces the TTL policy; call it before the cache is used
+func (c *RedisCache) SetTTLPolicy(p TTLPolicy) {
+	c.ttl = p
+}
+
+// Get retrieves price data from Redis, reading the key's remaining TTL in
+// the same round-trip to spot hits that almost missed
 func (c *RedisCache) Get(key string) (*types.PriceData, error) {
 	ctx := context.Background()
-	data, err := c.client.Get(ctx, key).Bytes()
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, c.prefix+key)
+	pttl := pipe.PTTL(ctx, c.prefix+key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	data, err := get.Bytes()
 	if err == redis.Nil {
 		return nil, nil
 	}
@@ -37,33 +118,181 @@ func (c *RedisCache) Get(key string) (*types.PriceData, error) {
 		return nil, err
 	}
 
-	var priceData types.PriceData
-	if err := json.Unmarshal(data, &priceData); err != nil {
-		return nil, err
+	c.checkExpiry(pttl)
+	return c.codec.Decode(data)
+}
+
+// checkExpiry counts a hit whose entry was about to expire; many of them
+// mean refreshes barely beat the TTL and misses are close behind
+func (c *RedisCache) checkExpiry(pttl *redis.DurationCmd) {
+	remaining, err := pttl.Result()
+	if err != nil || remaining < 0 {
+		return // no expiry set, or the key is already gone
+	}
+	if remaining <= c.ttl.NearExpiry {
+		c.metrics.RecordCacheNearExpiryHit()
 	}
-	return &priceData, nil
 }
 
-// Set stores price data in Redis with a TTL
+// Set stores price data in Redis with a TTL; nil data removes the key
 func (c *RedisCache) Set(key string, data *types.PriceData, tierType string) error {
 	ctx := context.Background()
-	dataBytes, err := json.Marshal(data)
+	if data == nil {
+		return c.client.Del(ctx, c.prefix+key).Err()
+	}
+	dataBytes, err := c.codec.Encode(data)
 	if err != nil {
 		return err
 	}
 
-	// Determine TTL based on tier type
-	var ttl time.Duration
-	switch tierType {
-	case "hot":
-		ttl = 10 * time.Second // hot assets short TTL
-	case "medium":
-		ttl = 1 * time.Minute // midium assets medium TTL
-	case "cold":
-		ttl = 5 * time.Minute // cold assets long TTL
-	default:
-		ttl = 5 * time.Minute
+	// Determine TTL based on tier type; unknown tiers get the coldest tier's TTL
+	ttl := c.ttl.ttl(c.tiers.Lookup(tierType))
+
+	// Store the price and drop any negative entry in one round-trip
+	pipe := c.client.Pipeline()
+	pipe.Set(ctx, c.prefix+key, dataBytes, ttl)
+	pipe.Del(ctx, c.prefix+unavailablePrefix+key)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SetFenced stores price data with a TTL unless a newer fencing token has
+// written the key; data must not be nil. The fence key is hash-tagged with
+// the price key so the script works on Redis Cluster too
+func (c *RedisCache) SetFenced(key string, data *types.PriceData, tierType string, token int64) (bool, error) {
+	dataBytes, err := c.codec.Encode(data)
+	if err != nil {
+		return false, err
+	}
+	ttl := c.ttl.ttl(c.tiers.Lookup(tierType))
+
+	ctx := context.Background()
+	keys := []string{c.prefix + key, "{" + c.prefix + key + "}" + fenceSuffix}
+	written, err := fencedSetScript.Run(ctx, c.client, keys, token, dataBytes, ttl.Milliseconds()).Int()
+	if err != nil || written == 0 {
+		return false, err
 	}
+	// The negative entry lives in another hash slot, so drop it separately
+	return true, c.client.Del(ctx, c.prefix+unavailablePrefix+key).Err()
+}
 
-	return c.client.Set(ctx, key, dataBytes, ttl).Err()
+// GetMany retrieves several prices with a single MGET
+// Entries that can't be decoded are treated as misses
+func (c *RedisCache) GetMany(keys []string) (map[string]*types.PriceData, error) {
+	result := make(map[string]*types.PriceData, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, pttls, err := c.fetchMany(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue // nil: key not in Redis
+		}
+		priceData, err := c.codec.Decode([]byte(str))
+		if err != nil {
+			continue
+		}
+		c.checkExpiry(pttls[i])
+		result[keys[i]] = priceData
+	}
+	return result, nil
+}
+
+// fetchMany reads the raw values and remaining TTLs of several keys in one
+// round-trip. Redis Cluster rejects an MGET spanning hash slots, so there the
+// GETs are pipelined instead and go-redis sends one batch per node
+func (c *RedisCache) fetchMany(keys []string) ([]interface{}, []*redis.DurationCmd, error) {
+	ctx := context.Background()
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+
+	pipe := c.client.Pipeline()
+	_, cluster := c.client.(*redis.ClusterClient)
+	var mget *redis.SliceCmd
+	gets := make([]*redis.StringCmd, len(prefixed))
+	pttls := make([]*redis.DurationCmd, len(prefixed))
+	if !cluster {
+		mget = pipe.MGet(ctx, prefixed...)
+	}
+	for i, key := range prefixed {
+		if cluster {
+			gets[i] = pipe.Get(ctx, key)
+		}
+		pttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+
+	if mget != nil {
+		values, err := mget.Result()
+		return values, pttls, err
+	}
+	values := make([]interface{}, len(gets))
+	for i, cmd := range gets {
+		if v, err := cmd.Result(); err == nil {
+			values[i] = v
+		}
+	}
+	return values, pttls, nil
+}
+
+// SetMany stores several prices in one pipelined round-trip
+func (c *RedisCache) SetMany(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := c.client.Pipeline()
+	for _, e := range entries {
+		if e.Data == nil {
+			pipe.Del(ctx, c.prefix+e.Key)
+			continue
+		}
+		dataBytes, err := c.codec.Encode(e.Data)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, c.prefix+e.Key, dataBytes, c.ttl.ttl(c.tiers.Lookup(e.Tier)))
+		pipe.Del(ctx, c.prefix+unavailablePrefix+e.Key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetUnavailable returns the negative entry of a key, or nil if there is none
+func (c *RedisCache) GetUnavailable(key string) (*Unavailable, error) {
+	ctx := context.Background()
+	data, err := c.client.Get(ctx, c.prefix+unavailablePrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var u Unavailable
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// SetUnavailable stores a negative entry that expires after ttl
+func (c *RedisCache) SetUnavailable(key string, u Unavailable, ttl time.Duration) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	return c.client.Set(ctx, c.prefix+unavailablePrefix+key, data, ttl).Err()
 }
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/tiers"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// testMetrics is shared by every test: the collectors register globally
+var testMetrics = metrics.NewMetricsService()
+
+func TestRedisCacheSetFenced(t *testing.T) {
+	// Each step writes the price with its token; the cache must keep the
+	// price of the newest token seen so far
+	steps := []struct {
+		token   int64
+		price   float64
+		written bool
+	}{
+		{token: 2, price: 10, written: true},
+		{token: 2, price: 11, written: true}, // same term keeps writing
+		{token: 1, price: 12, written: false},
+		{token: 3, price: 13, written: true},
+		{token: 2, price: 14, written: false},
+	}
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	c := NewRedisCache(client, tiers.Default(), "test:", JSONCodec{}, testMetrics)
+
+	want := 0.0
+	for _, step := range steps {
+		data := &types.PriceData{Asset: "asset1", Price: step.price, Timestamp: time.Now().Unix()}
+		written, err := c.SetFenced("asset1", data, "hot", step.token)
+		if err != nil {
+			t.Fatalf("token %d: %v", step.token, err)
+		}
+		if written != step.written {
+			t.Errorf("token %d: written = %v, want %v", step.token, written, step.written)
+		}
+		if written {
+			want = step.price
+		}
+
+		got, err := c.Get("asset1")
+		if err != nil || got == nil {
+			t.Fatalf("token %d: Get = %v, %v", step.token, got, err)
+		}
+		if got.Price != want {
+			t.Errorf("token %d: cached price %v, want %v", step.token, got.Price, want)
+		}
+	}
+	if ttl := mr.TTL("test:asset1"); ttl <= 0 {
+		t.Errorf("fenced write left no TTL on the price (%v)", ttl)
+	}
+}
+
+func TestRedisCacheSetFencedClearsUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	c := NewRedisCache(client, tiers.Default(), "", JSONCodec{}, testMetrics)
+
+	if err := c.SetUnavailable("asset1", Unavailable{Reason: "down"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	data := &types.PriceData{Asset: "asset1", Price: 1, Timestamp: time.Now().Unix()}
+	if _, err := c.SetFenced("asset1", data, "hot", 1); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := c.GetUnavailable("asset1"); err != nil || u != nil {
+		t.Errorf("GetUnavailable = %+v, %v after a fenced write, want nil", u, err)
+	}
+}
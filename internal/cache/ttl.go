@@ -0,0 +1,48 @@
+// internal/cache/ttl.go
+package cache
+
+import (
+	"math/rand"
+	"time"
+
+	"real-time-price-aggregator/internal/tiers"
+)
+
+// TTLPolicy controls how long cached prices live
+// Entries outlive their refresh interval by a safety margin so the next
+// refresh overwrites them before they expire, and random jitter keeps entries
+// written together from expiring together
+type TTLPolicy struct {
+	// Multiplier scales the tier's refresh interval; 0 uses the tier's cache_ttl as is
+	Multiplier float64
+	// Jitter adds up to this fraction of the TTL at random
+	Jitter float64
+	// NearExpiry is how close to expiry a hit must be to count as near-expiry
+	NearExpiry time.Duration
+}
+
+// DefaultTTLPolicy returns the policy used unless SetTTLPolicy is called
+func DefaultTTLPolicy() TTLPolicy {
+	return TTLPolicy{
+		Multiplier: 2,
+		Jitter:     0.1,
+		NearExpiry: time.Second,
+	}
+}
+
+// ttl returns the TTL of an entry written for a tier
+// The tier's cache_ttl remains a lower bound
+func (p TTLPolicy) ttl(tier tiers.Tier) time.Duration {
+	ttl := tier.CacheTTL.Duration
+	if p.Multiplier > 0 {
+		if derived := time.Duration(float64(tier.RefreshInterval.Duration) * p.Multiplier); derived > ttl {
+			ttl = derived
+		}
+	}
+	if p.Jitter > 0 && ttl > 0 {
+		if spread := int64(float64(ttl) * p.Jitter); spread > 0 {
+			ttl += time.Duration(rand.Int63n(spread))
+		}
+	}
+	return ttl
+}
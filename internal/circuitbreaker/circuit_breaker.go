@@ -126,3 +126,17 @@ func (cb *CircuitBreaker) GetState() State {
 	defer cb.mutex.Unlock()
 	return cb.state
 }
+
+// Allow reports whether Execute would currently run a call, without changing
+// the state; an open circuit allows one once its reset timeout has passed
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	switch cb.state {
+	case Open:
+		return time.Since(cb.lastFailure) > cb.resetTimeout
+	case HalfOpen:
+		return cb.retryCount < cb.halfOpenMaxRetries
+	}
+	return true
+}
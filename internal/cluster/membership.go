@@ -0,0 +1,197 @@
+// internal/cluster/membership.go
+package cluster
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"real-time-price-aggregator/internal/metrics"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// virtualNodes is the number of ring points per member
+const virtualNodes = 100
+
+// Membership keeps this replica registered in a Redis sorted set (scored by
+// last heartbeat) and maintains a hash ring over the members that are alive
+type Membership struct {
+	client   redis.UniversalClient
+	key      string
+	id       string
+	ttl      time.Duration // members silent for longer are considered dead
+	onChange func(ring *Ring)
+	metrics  *metrics.MetricsService
+	mutex    sync.RWMutex
+	ring     *Ring
+	lastBeat time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewMembership creates a membership for replica id. onChange runs whenever
+// the set of live members changes
+func NewMembership(
+	client redis.UniversalClient,
+	key string,
+	id string,
+	ttl time.Duration,
+	onChange func(ring *Ring),
+	m *metrics.MetricsService,
+) *Membership {
+	return &Membership{
+		client:   client,
+		key:      key,
+		id:       id,
+		ttl:      ttl,
+		onChange: onChange,
+		metrics:  m,
+		ring:     NewRing(nil, virtualNodes),
+	}
+}
+
+// ID returns this replica's member id
+func (m *Membership) ID() string {
+	return m.id
+}
+
+// Owns reports whether this replica owns the given asset on the current ring
+func (m *Membership) Owns(asset string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.ring.Owner(asset) == m.id
+}
+
+// Ring returns the current hash ring
+func (m *Membership) Ring() *Ring {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.ring
+}
+
+// Start joins the cluster and heartbeats every ttl/3 in the background
+func (m *Membership) Start() {
+	m.mutex.Lock()
+	if m.stop != nil {
+		m.mutex.Unlock()
+		return
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	stop, done := m.stop, m.done
+	m.mutex.Unlock()
+
+	log.Printf("Joining refresh cluster as %s", m.id)
+	m.heartbeat()
+	go m.run(stop, done)
+}
+
+// Stop leaves the cluster so the remaining members pick up this replica's assets
+func (m *Membership) Stop() {
+	m.mutex.Lock()
+	stop, done := m.stop, m.done
+	m.stop = nil
+	m.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.client.ZRem(ctx, m.key, m.id).Err(); err != nil {
+		log.Printf("Failed to leave refresh cluster: %v", err)
+	}
+	m.apply(nil)
+	log.Printf("Left refresh cluster")
+}
+
+// run heartbeats until stopped
+func (m *Membership) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.heartbeat()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// heartbeat refreshes this member's score, prunes dead members and
+// rebuilds the ring if the live set changed
+func (m *Membership) heartbeat() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.ttl/3)
+	defer cancel()
+
+	now := time.Now()
+	cutoff := strconv.FormatInt(now.Add(-m.ttl).UnixMilli(), 10)
+
+	pipe := m.client.TxPipeline()
+	pipe.ZAdd(ctx, m.key, &redis.Z{Score: float64(now.UnixMilli()), Member: m.id})
+	pipe.ZRemRangeByScore(ctx, m.key, "-inf", "("+cutoff)
+	live := pipe.ZRangeByScore(ctx, m.key, &redis.ZRangeBy{Min: cutoff, Max: "+inf"})
+	if _, err := pipe.Exec(ctx); err != nil {
+		// Without a heartbeat we can't tell who else is alive; keep the old
+		// ring until our own entry would have expired, then drop all ownership
+		log.Printf("Cluster heartbeat failed: %v", err)
+		m.mutex.RLock()
+		expired := time.Since(m.lastBeat) > m.ttl
+		m.mutex.RUnlock()
+		if expired {
+			m.apply(nil)
+		}
+		return
+	}
+
+	m.mutex.Lock()
+	m.lastBeat = now
+	m.mutex.Unlock()
+	m.apply(live.Val())
+}
+
+// apply rebuilds the ring when the member list differs from the current one
+func (m *Membership) apply(members []string) {
+	m.mutex.Lock()
+	current := m.ring.Members()
+	if sameMembers(current, members) {
+		m.mutex.Unlock()
+		return
+	}
+	ring := NewRing(members, virtualNodes)
+	m.ring = ring
+	m.mutex.Unlock()
+
+	log.Printf("Cluster membership changed: %d live members %v", len(ring.Members()), ring.Members())
+	m.metrics.RecordClusterMembers(len(ring.Members()))
+	if m.onChange != nil {
+		m.onChange(ring)
+	}
+}
+
+// sameMembers compares a sorted member list with an unsorted one
+func sameMembers(sorted, members []string) bool {
+	if len(sorted) != len(members) {
+		return false
+	}
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		seen[member] = true
+	}
+	for _, member := range sorted {
+		if !seen[member] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// internal/cluster/ring.go
+package cluster
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Ring is an immutable consistent hash ring over the live members
+// Each member is placed at several virtual points so assets spread evenly
+// and only about 1/N of them move when a member joins or leaves
+type Ring struct {
+	points  []uint32
+	owners  map[uint32]string
+	members []string
+}
+
+// NewRing builds a ring with vnodes virtual points per member
+func NewRing(members []string, vnodes int) *Ring {
+	r := &Ring{
+		points:  make([]uint32, 0, len(members)*vnodes),
+		owners:  make(map[uint32]string, len(members)*vnodes),
+		members: append([]string(nil), members...),
+	}
+	sort.Strings(r.members)
+
+	for _, member := range r.members {
+		for i := 0; i < vnodes; i++ {
+			point := hashKey(member + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[point]; taken {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// Owner returns the member responsible for a key ("" if the ring is empty)
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0 // wrap around
+	}
+	return r.owners[r.points[i]]
+}
+
+// Members returns the members on the ring in sorted order
+func (r *Ring) Members() []string {
+	return append([]string(nil), r.members...)
+}
+
+// hashKey hashes a key onto the ring
+// FNV alone clusters similar keys such as "asset1", "asset2", so the result
+// goes through the murmur3 finalizer to spread them around the ring
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+)
+
+// ringKeys returns n asset names shaped like the real ones
+func ringKeys(n int) []string {
+	keys := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		keys = append(keys, fmt.Sprintf("asset%d", i))
+	}
+	return keys
+}
+
+// ringMembers returns n replica IDs
+func ringMembers(n int) []string {
+	members := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		members = append(members, fmt.Sprintf("replica-%d", i))
+	}
+	return members
+}
+
+func TestRingDistribution(t *testing.T) {
+	keys := ringKeys(10000)
+
+	for _, n := range []int{2, 3, 5, 10} {
+		t.Run(fmt.Sprintf("%d members", n), func(t *testing.T) {
+			ring := NewRing(ringMembers(n), 100)
+			owned := make(map[string]int)
+			for _, key := range keys {
+				owned[ring.Owner(key)]++
+			}
+
+			// Every member gets a share within half of a fair one
+			fair := len(keys) / n
+			for _, member := range ringMembers(n) {
+				if got := owned[member]; got < fair/2 || got > fair*3/2 {
+					t.Errorf("%s owns %d keys, want about %d", member, got, fair)
+				}
+			}
+		})
+	}
+}
+
+func TestRingMoves(t *testing.T) {
+	keys := ringKeys(10000)
+
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+	}{
+		{name: "join", before: ringMembers(4), after: ringMembers(5)},
+		{name: "leave", before: ringMembers(5), after: ringMembers(4)},
+		{name: "replace", before: ringMembers(4), after: []string{"replica-1", "replica-2", "replica-3", "replica-9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := NewRing(tt.before, 100)
+			after := NewRing(tt.after, 100)
+
+			moved := 0
+			for _, key := range keys {
+				from, to := before.Owner(key), after.Owner(key)
+				if from == to {
+					continue
+				}
+				moved++
+				// Keys only move off a member that left or onto one that joined
+				if containsMember(tt.before, to) && containsMember(tt.after, from) {
+					t.Fatalf("%s moved from %s to %s, though both are on both rings", key, from, to)
+				}
+			}
+
+			// About 1/N of the keys move, N being the larger ring
+			n := len(tt.before)
+			if len(tt.after) > n {
+				n = len(tt.after)
+			}
+			if limit := 2 * len(keys) / n; moved == 0 || moved > limit {
+				t.Errorf("%d of %d keys moved, want between 1 and %d", moved, len(keys), limit)
+			}
+		})
+	}
+}
+
+// containsMember reports whether member is in members
+func containsMember(members []string, member string) bool {
+	for _, m := range members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRingOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		want    string
+	}{
+		{name: "empty", members: nil, want: ""},
+		{name: "single", members: []string{"replica-1"}, want: "replica-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := NewRing(tt.members, 100)
+			for _, key := range ringKeys(100) {
+				if got := ring.Owner(key); got != tt.want {
+					t.Fatalf("Owner(%s) = %q, want %q", key, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRingIgnoresMemberOrder(t *testing.T) {
+	a := NewRing([]string{"replica-1", "replica-2", "replica-3"}, 100)
+	b := NewRing([]string{"replica-3", "replica-1", "replica-2"}, 100)
+	for _, key := range ringKeys(1000) {
+		if a.Owner(key) != b.Owner(key) {
+			t.Fatalf("%s is owned by %s or %s depending on member order", key, a.Owner(key), b.Owner(key))
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// internal/events/broker.go
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Limits of resuming a subscriber from the stream, when the buffer of
+// recent updates doesn't reach back far enough
+const (
+	replayPage  = 1000
+	replayLimit = 10000 // a subscriber further behind starts over
+)
+
+// Event is a price update
+type Event struct {
+	// ID is the update's stream entry ID, the same on every replica; it is
+	// empty if the stream is disabled
+	ID   string
+	Data types.PriceData
+	seq  uint64 // order in which this replica received it
+}
+
+// before reports whether e is an older update than other
+func (e Event) before(other Event) bool {
+	if e.ID != "" && other.ID != "" {
+		return compareStreamIDs(e.ID, other.ID) < 0
+	}
+	return e.seq < other.seq
+}
+
+// Broker receives price updates from every replica over Redis pub/sub and
+// fans them out to local subscribers such as streaming HTTP clients
+// Updates carry their stream entry ID, the same on every replica. A client
+// resumes from the broker's buffer of recent updates, or, if it reconnects
+// to a replica whose buffer doesn't reach back to the last ID it saw, by
+// replaying the stream. The broker never blocks on a slow subscriber: one
+// that falls behind only gets the newest update of each asset
+type Broker struct {
+	client    redis.UniversalClient
+	publisher *Publisher // names the channels and replays the stream
+	metrics   *metrics.MetricsService
+
+	mutex       sync.Mutex
+	seq         uint64
+	subscribers map[*Subscription]struct{}
+
+	// buffer holds up to bufferSize of the latest updates in stream order;
+	// it has every update after floor ("" when it has none yet)
+	buffer     []Event
+	bufferSize int
+	floor      string
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+// NewBroker creates a broker for the updates of a publisher that buffers
+// the latest bufferSize of them for resuming subscribers
+func NewBroker(client redis.UniversalClient, p *Publisher, bufferSize int, m *metrics.MetricsService) *Broker {
+	return &Broker{
+		client:      client,
+		publisher:   p,
+		metrics:     m,
+		subscribers: make(map[*Subscription]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Start subscribes to price updates
+func (b *Broker) Start() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.pubsub != nil {
+		return
+	}
+	b.pubsub = b.client.PSubscribe(context.Background(), b.publisher.Channel("*"))
+	b.done = make(chan struct{})
+
+	go b.listen(b.pubsub, b.done)
+}
+
+// Stop unsubscribes from price updates; existing subscriptions stay open
+// but receive nothing more
+func (b *Broker) Stop() {
+	b.mutex.Lock()
+	pubsub, done := b.pubsub, b.done
+	b.pubsub = nil
+	b.mutex.Unlock()
+
+	if pubsub == nil {
+		return
+	}
+	pubsub.Close()
+	<-done
+}
+
+// listen dispatches updates until the subscription closes
+// go-redis resubscribes after connection errors; updates published
+// meanwhile are lost, so the buffer starts over
+func (b *Broker) listen(pubsub *redis.PubSub, done chan<- struct{}) {
+	defer close(done)
+
+	for received := range pubsub.ChannelWithSubscriptions(context.Background(), 100) {
+		msg, ok := received.(*redis.Message)
+		if !ok {
+			b.resetBuffer() // (re)subscribed
+			continue
+		}
+		var update message
+		if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+			log.Printf("Ignoring malformed price update on %s: %v", msg.Channel, err)
+			continue
+		}
+		b.dispatch(Event{ID: update.ID, Data: update.PriceData})
+	}
+}
+
+// dispatch hands an update to interested subscribers
+func (b *Broker) dispatch(event Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.seq++
+	event.seq = b.seq
+	b.bufferEvent(event)
+	for sub := range b.subscribers {
+		if sub.offer(event) {
+			b.metrics.RecordStreamDropped()
+		}
+	}
+}
+
+// bufferEvent adds an update to the buffer, keeping it in stream order;
+// replicas publish concurrently, so updates may arrive slightly out of order
+// The caller holds the mutex
+func (b *Broker) bufferEvent(event Event) {
+	if b.bufferSize <= 0 || event.ID == "" {
+		return
+	}
+	if b.floor == "" {
+		b.floor = event.ID
+	} else if compareStreamIDs(event.ID, b.floor) <= 0 {
+		return // older than what the buffer has already given up
+	}
+
+	b.buffer = append(b.buffer, event)
+	for i := len(b.buffer) - 1; i > 0 && b.buffer[i].before(b.buffer[i-1]); i-- {
+		b.buffer[i], b.buffer[i-1] = b.buffer[i-1], b.buffer[i]
+	}
+	if len(b.buffer) > b.bufferSize {
+		b.floor = b.buffer[0].ID
+		b.buffer = b.buffer[1:]
+	}
+}
+
+// resetBuffer empties the buffer after updates may have been missed
+func (b *Broker) resetBuffer() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.buffer = nil
+	b.floor = ""
+}
+
+// Subscribe registers a subscriber for the given assets (all assets if
+// empty). If lastID is set, the updates after it are queued first, from the
+// buffer or else the stream; resumed is false if that is impossible because
+// lastID is unknown, already trimmed or too far behind, so the subscriber
+// has missed updates and should re-read current prices
+func (b *Broker) Subscribe(assets []string, lastID string) (sub *Subscription, resumed bool) {
+	sub = newSubscription(assets)
+
+	// Register before replaying so no update falls in between; updates seen
+	// both ways are only delivered once
+	b.mutex.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.metrics.RecordStreamClients(len(b.subscribers))
+	b.mutex.Unlock()
+
+	if lastID == "" {
+		return sub, true
+	}
+	if _, _, ok := parseStreamID(lastID); !ok {
+		return sub, false
+	}
+	if b.replayBuffered(sub, lastID) {
+		return sub, true
+	}
+	return sub, b.replay(sub, lastID)
+}
+
+// replayBuffered queues the buffered updates after lastID, reporting
+// whether the buffer reaches back that far
+func (b *Broker) replayBuffered(sub *Subscription, lastID string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.floor == "" || compareStreamIDs(lastID, b.floor) < 0 {
+		return false
+	}
+	start := sort.Search(len(b.buffer), func(i int) bool {
+		return compareStreamIDs(b.buffer[i].ID, lastID) > 0
+	})
+	for _, event := range b.buffer[start:] {
+		sub.offer(event)
+	}
+	return true
+}
+
+// replay queues the stream entries after lastID, reporting whether it got
+// all of them
+func (b *Broker) replay(sub *Subscription, lastID string) bool {
+	if b.publisher.streamLen <= 0 {
+		return false
+	}
+	oldest, err := b.publisher.Oldest()
+	if err != nil {
+		log.Printf("Failed to resume price stream after %s: %v", lastID, err)
+		return false
+	}
+	// Entries right after lastID may have been trimmed with it
+	if oldest == "" || compareStreamIDs(lastID, oldest) < 0 {
+		return false
+	}
+
+	after := lastID
+	for read := 0; read < replayLimit; {
+		updates, err := b.publisher.Read(after, replayPage)
+		if err != nil {
+			log.Printf("Failed to resume price stream after %s: %v", lastID, err)
+			return false
+		}
+		for _, update := range updates {
+			sub.offer(Event{ID: update.ID, Data: update.Data})
+		}
+		if len(updates) < replayPage {
+			return true
+		}
+		read += len(updates)
+		after = updates[len(updates)-1].ID
+	}
+	return false
+}
+
+// Unsubscribe removes a subscriber
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.subscribers, sub)
+	b.metrics.RecordStreamClients(len(b.subscribers))
+}
+
+// Subscription is one subscriber's view of the broker
+// Pending updates are kept per asset, so a newer update of an asset replaces
+// one the subscriber hasn't taken yet
+type Subscription struct {
+	mutex   sync.Mutex
+	all     bool
+	assets  map[string]bool
+	pending map[string]Event
+	latest  map[string]Event // newest update offered per asset, to skip older ones
+	notify  chan struct{}
+}
+
+// newSubscription creates a subscription for assets, or all assets if empty
+func newSubscription(assets []string) *Subscription {
+	sub := &Subscription{
+		all:     len(assets) == 0,
+		assets:  make(map[string]bool, len(assets)),
+		pending: make(map[string]Event),
+		latest:  make(map[string]Event),
+		notify:  make(chan struct{}, 1),
+	}
+	for _, asset := range assets {
+		sub.assets[asset] = true
+	}
+	return sub
+}
+
+// offer queues an event if the subscriber wants its asset and hasn't got it
+// or a newer one yet, reporting whether an older update still waiting was
+// dropped for it
+func (s *Subscription) offer(event Event) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	asset := event.Data.Asset
+	if !s.all && !s.assets[asset] {
+		return false
+	}
+	if latest, ok := s.latest[asset]; ok && !latest.before(event) {
+		return false
+	}
+	s.latest[asset] = event
+	_, dropped := s.pending[event.Data.Asset]
+	s.pending[event.Data.Asset] = event
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// Add subscribes to more assets
+func (s *Subscription) Add(assets ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, asset := range assets {
+		s.assets[asset] = true
+	}
+}
+
+// Remove unsubscribes from assets and discards their pending updates
+func (s *Subscription) Remove(assets ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, asset := range assets {
+		delete(s.assets, asset)
+		delete(s.pending, asset)
+		delete(s.latest, asset)
+	}
+}
+
+// Assets returns the subscribed assets, sorted; nil means all assets
+func (s *Subscription) Assets() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.all {
+		return nil
+	}
+	assets := make([]string, 0, len(s.assets))
+	for asset := range s.assets {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// Ready is signalled when updates are waiting to be taken
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.notify
+}
+
+// Take returns the waiting updates in the order they were received
+func (s *Subscription) Take() []Event {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = make(map[string]Event, len(pending))
+	s.mutex.Unlock()
+
+	events := make([]Event, 0, len(pending))
+	for _, event := range pending {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].before(events[j])
+	})
+	return events
+}
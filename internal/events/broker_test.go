@@ -0,0 +1,92 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/types"
+)
+
+// testMetrics is shared by every test: the collectors register globally
+var testMetrics = metrics.NewMetricsService()
+
+// bufferedBroker returns a broker with the stream disabled, so subscribers
+// can only be resumed from its buffer, after receiving updates with the
+// given IDs, each for its own asset
+func bufferedBroker(size int, ids ...string) *Broker {
+	b := NewBroker(nil, NewPublisher(nil, "", 0, testMetrics), size, testMetrics)
+	for _, id := range ids {
+		b.dispatch(Event{ID: id, Data: types.PriceData{Asset: "asset-" + id}})
+	}
+	return b
+}
+
+// takenIDs returns the IDs of the updates waiting for a subscriber
+func takenIDs(sub *Subscription) string {
+	ids := []string{}
+	for _, event := range sub.Take() {
+		ids = append(ids, event.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestBrokerResumeFromBuffer(t *testing.T) {
+	// Updates arrive slightly out of order; with room for three the buffer
+	// keeps 3-0, 4-0 and 5-0 and has every update after 2-0
+	received := []string{"1-0", "2-0", "4-0", "3-0", "5-0"}
+
+	tests := []struct {
+		name    string
+		lastID  string
+		resumed bool
+		replay  string
+	}{
+		{name: "at the floor", lastID: "2-0", resumed: true, replay: "3-0,4-0,5-0"},
+		{name: "within the buffer", lastID: "3-0", resumed: true, replay: "4-0,5-0"},
+		{name: "between entries", lastID: "3-5", resumed: true, replay: "4-0,5-0"},
+		{name: "up to date", lastID: "5-0", resumed: true, replay: ""},
+		{name: "ahead of this replica", lastID: "9-0", resumed: true, replay: ""},
+		{name: "older than the buffer", lastID: "1-0", resumed: false, replay: ""},
+		{name: "invalid id", lastID: "abc", resumed: false, replay: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bufferedBroker(3, received...)
+			sub, resumed := b.Subscribe(nil, tt.lastID)
+			defer b.Unsubscribe(sub)
+
+			if resumed != tt.resumed {
+				t.Errorf("resumed = %v, want %v", resumed, tt.resumed)
+			}
+			if got := takenIDs(sub); got != tt.replay {
+				t.Errorf("replayed %q, want %q", got, tt.replay)
+			}
+		})
+	}
+}
+
+func TestBrokerBufferIgnoresUpdatesBelowFloor(t *testing.T) {
+	b := bufferedBroker(2, "1-0", "2-0", "3-0") // floor 1-0
+	b.dispatch(Event{ID: "0-5", Data: types.PriceData{Asset: "late"}})
+
+	sub, resumed := b.Subscribe(nil, "1-0")
+	defer b.Unsubscribe(sub)
+	if !resumed {
+		t.Fatal("resume at the floor failed")
+	}
+	if got := takenIDs(sub); got != "2-0,3-0" {
+		t.Errorf("replayed %q, want \"2-0,3-0\": 0-5 is older than what the buffer gave up", got)
+	}
+}
+
+func TestBrokerResetBuffer(t *testing.T) {
+	b := bufferedBroker(3, "1-0", "2-0")
+	b.resetBuffer()
+
+	sub, resumed := b.Subscribe(nil, "1-0")
+	defer b.Unsubscribe(sub)
+	if resumed {
+		t.Error("resumed from a buffer that was reset after a resubscription")
+	}
+}
@@ -0,0 +1,268 @@
+// internal/events/publisher.go
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/types"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Default Redis names for price updates
+const (
+	// ChannelPrefix is followed by the asset symbol; PSUBSCRIBE prices:updates:* receives every asset
+	ChannelPrefix = "prices:updates:"
+	// StreamKey is the append-only stream holding recent updates of every asset
+	StreamKey = "prices:stream"
+	// HistoryPrefix is followed by the asset symbol; each asset keeps its own
+	// short stream so its history survives the other assets' updates
+	HistoryPrefix = "prices:history:"
+)
+
+// Update is a price update read back from the stream
+type Update struct {
+	ID   string          `json:"id"` // stream entry ID, usable as a replay offset
+	Tier string          `json:"tier"`
+	Data types.PriceData `json:"data"`
+}
+
+// message is the pub/sub payload: the price and, when the stream is
+// enabled, the ID of its stream entry
+type message struct {
+	types.PriceData
+	ID string `json:"id,omitempty"`
+}
+
+// Publisher fans out refreshed prices to Redis: a pub/sub message on the
+// asset's channel for live subscribers, an entry in a length-bounded stream
+// for consumers that need to replay from an offset and one in the asset's
+// history stream
+type Publisher struct {
+	client     redis.UniversalClient
+	prefix     string // namespace prepended to channel and stream names
+	streamLen  int64  // approximate MAXLEN of the stream; 0 disables the stream
+	historyLen int64  // MAXLEN of each asset's history stream; 0 disables them
+	breaker    func(fn func() error) error
+	metrics    *metrics.MetricsService
+}
+
+// NewPublisher creates a publisher keeping about streamLen updates in the stream
+func NewPublisher(client redis.UniversalClient, prefix string, streamLen int64, m *metrics.MetricsService) *Publisher {
+	return &Publisher{client: client, prefix: prefix, streamLen: streamLen, metrics: m}
+}
+
+// SetHistoryLen sets how many updates each asset's history stream keeps
+// (0 disables them); call it before publishing
+func (p *Publisher) SetHistoryLen(n int64) {
+	p.historyLen = n
+}
+
+// SetBreaker makes Publish run through a circuit breaker's execute function,
+// typically the cache's, so a Redis outage skips publishing instead of
+// waiting for timeouts on every refresh
+func (p *Publisher) SetBreaker(execute func(fn func() error) error) {
+	p.breaker = execute
+}
+
+// HistoryLen returns how many updates each asset's history stream keeps
+func (p *Publisher) HistoryLen() int64 {
+	return p.historyLen
+}
+
+// HistoryStream returns the name of an asset's history stream
+func (p *Publisher) HistoryStream(asset string) string {
+	return p.prefix + HistoryPrefix + asset
+}
+
+// Channel returns the pub/sub channel for an asset
+func (p *Publisher) Channel(asset string) string {
+	return p.prefix + ChannelPrefix + asset
+}
+
+// Stream returns the name of the update stream
+func (p *Publisher) Stream() string {
+	return p.prefix + StreamKey
+}
+
+// Publish announces a refreshed price. The stream entry is added first so
+// the pub/sub message can carry its ID, which is the same on every replica
+// and lets subscribers resume from the stream
+func (p *Publisher) Publish(data *types.PriceData, tier string) error {
+	if p.breaker == nil {
+		return p.publish(data, tier)
+	}
+	ran := false
+	err := p.breaker(func() error {
+		ran = true
+		return p.publish(data, tier)
+	})
+	if !ran {
+		p.metrics.RecordPricePublish("skipped")
+	}
+	return err
+}
+
+// publish writes the update to the stream, the channel and the history
+func (p *Publisher) publish(data *types.PriceData, tier string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	values := map[string]interface{}{
+		"asset":        data.Asset,
+		"price":        strconv.FormatFloat(data.Price, 'f', -1, 64),
+		"last_updated": data.Timestamp,
+		"tier":         tier,
+	}
+	msg := message{PriceData: *data}
+	var streamErr error
+	if p.streamLen > 0 {
+		// Live subscribers still get the update if the stream write fails
+		msg.ID, streamErr = p.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.Stream(),
+			MaxLen: p.streamLen,
+			Approx: true, // trimming whole macro nodes is much cheaper
+			Values: values,
+		}).Result()
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	pipe := p.client.Pipeline()
+	pipe.Publish(ctx, p.Channel(data.Asset), payload)
+	if p.historyLen > 0 {
+		// History entries share the stream entry's ID when there is one
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.HistoryStream(data.Asset),
+			MaxLen: p.historyLen,
+			ID:     msg.ID,
+			Values: values,
+		})
+	}
+	_, err = pipe.Exec(ctx)
+	if err == nil {
+		err = streamErr
+	}
+	if err != nil {
+		p.metrics.RecordPricePublish("error")
+		return err
+	}
+	p.metrics.RecordPricePublish("ok")
+	return nil
+}
+
+// Read returns up to count updates recorded after the given stream ID
+// ("0" reads from the oldest retained entry) without blocking
+func (p *Publisher) Read(after string, count int64) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	streams, err := p.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{p.Stream(), after},
+		Count:   count,
+		Block:   -1,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			updates = append(updates, parseUpdate(msg))
+		}
+	}
+	return updates, nil
+}
+
+// Oldest returns the ID of the oldest update still in the stream, or "" if
+// it is empty
+func (p *Publisher) Oldest() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msgs, err := p.client.XRangeN(ctx, p.Stream(), "-", "+", 1).Result()
+	if err != nil || len(msgs) == 0 {
+		return "", err
+	}
+	return msgs[0].ID, nil
+}
+
+// History returns up to limit of the latest updates of an asset from its
+// history stream, newest first
+func (p *Publisher) History(asset string, limit int64) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msgs, err := p.client.XRevRangeN(ctx, p.HistoryStream(asset), "+", "-", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+	updates := make([]Update, 0, len(msgs))
+	for _, msg := range msgs {
+		updates = append(updates, parseUpdate(msg))
+	}
+	return updates, nil
+}
+
+// parseUpdate converts a stream entry to an Update; malformed fields are left zero
+func parseUpdate(msg redis.XMessage) Update {
+	update := Update{ID: msg.ID}
+	if v, ok := msg.Values["asset"].(string); ok {
+		update.Data.Asset = v
+	}
+	if v, ok := msg.Values["price"].(string); ok {
+		update.Data.Price, _ = strconv.ParseFloat(v, 64)
+	}
+	if v, ok := msg.Values["last_updated"].(string); ok {
+		update.Data.Timestamp, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if v, ok := msg.Values["tier"].(string); ok {
+		update.Tier = v
+	}
+	return update
+}
+
+// parseStreamID splits a stream entry ID ("<ms>-<seq>", or just "<ms>")
+func parseStreamID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, hasSeq := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqPart, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return ms, seq, true
+}
+
+// compareStreamIDs orders two valid stream entry IDs like strings.Compare
+func compareStreamIDs(a, b string) int {
+	aMS, aSeq, _ := parseStreamID(a)
+	bMS, bSeq, _ := parseStreamID(b)
+	switch {
+	case aMS != bMS:
+		if aMS < bMS {
+			return -1
+		}
+		return 1
+	case aSeq != bSeq:
+		if aSeq < bSeq {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"real-time-price-aggregator/internal/circuitbreaker"
 	"real-time-price-aggregator/internal/metrics"
+	"real-time-price-aggregator/internal/ratelimiter"
 	"real-time-price-aggregator/internal/types"
 	"strings"
 	"sync"
@@ -24,11 +25,24 @@ var (
 	ErrAssetNotSupported = errors.New("asset not supported")
 	ErrNoValidData       = errors.New("no valid data received from any endpoint")
 	ErrZeroVolume        = errors.New("total volume is zero, cannot calculate weighted average")
+	ErrRateLimited       = errors.New("request budget exhausted for all endpoints")
 )
 
+// criticalWaitTimeout is how long a critical request waits for a token
+const criticalWaitTimeout = 2 * time.Second
+
 // Fetcher interface defines price fetching operations
 type Fetcher interface {
+	// FetchPrice fetches a price at critical priority, waiting briefly for budget
 	FetchPrice(symbol string) (*types.PriceData, error)
+	// FetchPriceWithPriority fetches a price only from endpoints that have budget left for the priority
+	FetchPriceWithPriority(symbol string, priority ratelimiter.Priority) (*types.PriceData, error)
+}
+
+// RateLimitConfig configures the per-exchange request budget
+type RateLimitConfig struct {
+	RequestsPerSecond float64 // zero or less disables rate limiting
+	Burst             int
 }
 
 // fetcher struct implements the Fetcher interface
@@ -36,6 +50,7 @@ type fetcher struct {
 	endpoints       []string
 	client          *http.Client
 	circuitBreakers map[string]*circuitbreaker.CircuitBreaker
+	rateLimiters    map[string]*ratelimiter.RateLimiter
 	metrics         *metrics.MetricsService
 }
 
@@ -48,14 +63,15 @@ type mockResponse struct {
 }
 
 // NewFetcher creates a new Fetcher instance
-func NewFetcher(endpoints []string, m *metrics.MetricsService) Fetcher {
+func NewFetcher(endpoints []string, limits RateLimitConfig, m *metrics.MetricsService) Fetcher {
 	// Initialize HTTP client with timeout
 	client := &http.Client{
 		Timeout: 5 * time.Second,
 	}
 
-	// Initialize circuit breakers for each endpoint
+	// Initialize circuit breakers and rate limiters for each endpoint
 	circuitBreakers := make(map[string]*circuitbreaker.CircuitBreaker)
+	rateLimiters := make(map[string]*ratelimiter.RateLimiter)
 	for _, endpoint := range endpoints {
 		name := strings.TrimPrefix(endpoint, "http://")
 		name = strings.TrimPrefix(name, "https://")
@@ -67,20 +83,56 @@ func NewFetcher(endpoints []string, m *metrics.MetricsService) Fetcher {
 			30*time.Second, // Reset timeout
 			2,              // Half-open max retries
 		)
+
+		// Every caller (refresher, ForceRefresh, /refresh) shares this bucket
+		rateLimiters[endpoint] = ratelimiter.New(name, limits.RequestsPerSecond, limits.Burst)
 	}
 
 	return &fetcher{
 		endpoints:       endpoints,
 		client:          client,
 		circuitBreakers: circuitBreakers,
+		rateLimiters:    rateLimiters,
 		metrics:         m,
 	}
 }
 
+// acquireBudget takes a token from the endpoint's rate limiter
+func (f *fetcher) acquireBudget(endpoint string, priority ratelimiter.Priority) error {
+	limiter := f.rateLimiters[endpoint]
+	defer func() { f.metrics.RecordExchangeBudget(endpoint, limiter.Available()) }()
+
+	if priority == ratelimiter.Critical {
+		if err := limiter.Wait(priority, criticalWaitTimeout); err != nil {
+			f.metrics.RecordExchangeRateLimited(endpoint, priority.String())
+			return err
+		}
+		return nil
+	}
+
+	if !limiter.TryAcquire(priority) {
+		f.metrics.RecordExchangeRateLimited(endpoint, priority.String())
+		return ratelimiter.ErrRateLimited
+	}
+	return nil
+}
+
 // fetchFromEndpoint fetches price data from a single endpoint
-func (f *fetcher) fetchFromEndpoint(endpoint, symbol string) (*mockResponse, error) {
+func (f *fetcher) fetchFromEndpoint(endpoint, symbol string, priority ratelimiter.Priority) (*mockResponse, error) {
 	url := fmt.Sprintf("%s/%s", endpoint, symbol)
 
+	// Don't spend budget on an exchange the circuit breaker would reject
+	if !f.circuitBreakers[endpoint].Allow() {
+		f.metrics.RecordCircuitBreakerState(endpoint, int(circuitbreaker.Open))
+		f.metrics.RecordExchangeError(endpoint, "circuit_open")
+		return nil, fmt.Errorf("circuit open for endpoint %s", endpoint)
+	}
+
+	// Respect the exchange's request budget before sending anything
+	if err := f.acquireBudget(endpoint, priority); err != nil {
+		return nil, err
+	}
+
 	// Record the request
 	f.metrics.RecordExchangeRequest(endpoint)
 	startTime := time.Now()
@@ -131,8 +183,13 @@ func (f *fetcher) fetchFromEndpoint(endpoint, symbol string) (*mockResponse, err
 	return &mockResp, nil
 }
 
-// FetchPrice fetches the price for a symbol from mock exchanges and calculates a weighted average
+// FetchPrice fetches the price for a symbol at critical priority
 func (f *fetcher) FetchPrice(symbol string) (*types.PriceData, error) {
+	return f.FetchPriceWithPriority(symbol, ratelimiter.Critical)
+}
+
+// FetchPriceWithPriority fetches the price for a symbol from mock exchanges and calculates a weighted average
+func (f *fetcher) FetchPriceWithPriority(symbol string, priority ratelimiter.Priority) (*types.PriceData, error) {
 	responses := make([]*mockResponse, 0, len(f.endpoints))
 	errors := make([]error, 0, len(f.endpoints))
 	var wg sync.WaitGroup
@@ -146,7 +203,7 @@ func (f *fetcher) FetchPrice(symbol string) (*types.PriceData, error) {
 			defer wg.Done()
 
 			// Check if the asset is supported
-			resp, err := f.fetchFromEndpoint(ep, symbol)
+			resp, err := f.fetchFromEndpoint(ep, symbol, priority)
 			if err != nil {
 				errorChan <- err
 				return
@@ -170,6 +227,17 @@ func (f *fetcher) FetchPrice(symbol string) (*types.PriceData, error) {
 
 	// Check if we have any valid responses
 	if len(responses) == 0 {
+		// Report a budget problem separately so callers can defer instead of failing
+		limited := 0
+		for _, err := range errors {
+			if err == ratelimiter.ErrRateLimited {
+				limited++
+			}
+		}
+		if limited > 0 && limited == len(errors) {
+			return nil, ErrRateLimited
+		}
+
 		var errMsg string
 		if len(errors) > 0 {
 			errMsg = errors[0].Error()
@@ -0,0 +1,240 @@
+// internal/leader/election.go
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"real-time-price-aggregator/internal/metrics"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// renewScript extends the lease only if this replica still owns it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lease only if this replica still owns it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Elector implements leader election with a Redis lease lock
+// The leader renews its lease every ttl/3; if it dies the lease expires and
+// another replica takes over on its next attempt. Every new term gets a
+// fencing token from an INCR counter, so writes of a leader paused past its
+// lease (GC, partition) can be rejected once its successor has written
+type Elector struct {
+	client      redis.UniversalClient
+	key         string
+	id          string
+	ttl         time.Duration
+	onElected   func()
+	onRevoked   func()
+	metrics     *metrics.MetricsService
+	mutex       sync.Mutex
+	isLeader    bool
+	token       int64
+	leaseExpiry time.Time
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewElector creates a new elector. onElected runs when this replica becomes
+// leader and onRevoked when it loses or gives up leadership
+func NewElector(
+	client redis.UniversalClient,
+	key string,
+	ttl time.Duration,
+	onElected func(),
+	onRevoked func(),
+	m *metrics.MetricsService,
+) *Elector {
+	return &Elector{
+		client:    client,
+		key:       key,
+		id:        ReplicaID(),
+		ttl:       ttl,
+		onElected: onElected,
+		onRevoked: onRevoked,
+		metrics:   m,
+	}
+}
+
+// ReplicaID returns an identifier for this process that is unique across replicas
+func ReplicaID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// Start begins campaigning for leadership in the background
+func (e *Elector) Start() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.stop != nil {
+		return
+	}
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	log.Printf("Starting leader election as %s (lease %v)", e.id, e.ttl)
+
+	go e.run(e.stop, e.done)
+}
+
+// Stop gives up leadership, releasing the lease so another replica can take over immediately
+func (e *Elector) Stop() {
+	e.mutex.Lock()
+	stop, done := e.stop, e.done
+	e.stop = nil
+	e.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// IsLeader reports whether this replica holds a lease that has not expired
+func (e *Elector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.isLeader && time.Now().Before(e.leaseExpiry)
+}
+
+// Token returns the fencing token of the current term (0 when not leader)
+// Tokens only ever increase, so a newer leader always holds a larger one
+func (e *Elector) Token() int64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.isLeader {
+		return 0
+	}
+	return e.token
+}
+
+// run campaigns or renews every ttl/3 until stopped
+func (e *Elector) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.tick()
+	for {
+		select {
+		case <-ticker.C:
+			e.tick()
+		case <-stop:
+			e.release()
+			return
+		}
+	}
+}
+
+// tick renews the lease when leading, or tries to acquire it otherwise
+func (e *Elector) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+	defer cancel()
+
+	attemptAt := time.Now()
+	if e.leading() {
+		renewed, err := renewScript.Run(ctx, e.client, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+		if err != nil || renewed == 0 {
+			// Step down rather than risk two leaders; the lease may still be ours
+			// but we can't prove it before it expires
+			log.Printf("Lost leadership (renew failed: %v)", err)
+			e.demote()
+			return
+		}
+		e.mutex.Lock()
+		e.leaseExpiry = attemptAt.Add(e.ttl)
+		e.mutex.Unlock()
+		return
+	}
+
+	acquired, err := e.client.SetNX(ctx, e.key, e.id, e.ttl).Result()
+	if err != nil {
+		log.Printf("Leader election attempt failed: %v", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	token, err := e.client.Incr(ctx, e.key+":fence").Result()
+	if err != nil {
+		log.Printf("Failed to get fencing token, releasing lease: %v", err)
+		releaseScript.Run(ctx, e.client, []string{e.key}, e.id)
+		return
+	}
+	e.promote(token, attemptAt.Add(e.ttl))
+}
+
+// leading reports whether this replica currently believes it is leader
+func (e *Elector) leading() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.isLeader
+}
+
+// promote records a new term and notifies the callback
+func (e *Elector) promote(token int64, expiry time.Time) {
+	e.mutex.Lock()
+	e.isLeader = true
+	e.token = token
+	e.leaseExpiry = expiry
+	e.mutex.Unlock()
+
+	log.Printf("Became leader (fencing token %d)", token)
+	e.metrics.RecordLeaderStatus(true)
+	if e.onElected != nil {
+		e.onElected()
+	}
+}
+
+// demote clears leadership and notifies the callback
+func (e *Elector) demote() {
+	e.mutex.Lock()
+	wasLeader := e.isLeader
+	e.isLeader = false
+	e.token = 0
+	e.mutex.Unlock()
+
+	if !wasLeader {
+		return
+	}
+	e.metrics.RecordLeaderStatus(false)
+	if e.onRevoked != nil {
+		e.onRevoked()
+	}
+}
+
+// release steps down and deletes the lease if this replica still owns it
+func (e *Elector) release() {
+	if !e.leading() {
+		return
+	}
+	e.demote()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.id).Err(); err != nil {
+		log.Printf("Failed to release leader lease: %v", err)
+		return
+	}
+	log.Println("Released leader lease")
+}
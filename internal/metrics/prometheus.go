@@ -14,6 +14,8 @@ type MetricsService struct {
 	// API request metrics
 	apiRequests        *prometheus.CounterVec
 	apiRequestDuration *prometheus.HistogramVec
+	grpcRequests       *prometheus.CounterVec
+	deprecatedRequests *prometheus.CounterVec
 
 	// Cache metrics
 	cacheHits        prometheus.Counter
@@ -21,6 +23,9 @@ type MetricsService struct {
 	cacheHitsCount   float64    // Internal counter for hits
 	cacheMissesCount float64    // Internal counter for misses
 	cacheMutex       sync.Mutex // Mutex to protect internal counters
+	l1Cache          *prometheus.CounterVec
+	negativeCache    *prometheus.CounterVec
+	nearExpiryHits   prometheus.Counter
 
 	// Exchange metrics
 	exchangeRequests *prometheus.CounterVec
@@ -30,12 +35,38 @@ type MetricsService struct {
 	// Circuit breaker metrics
 	circuitBreakerState *prometheus.GaugeVec
 
+	// Rate limit metrics
+	exchangeRateLimited *prometheus.CounterVec
+	exchangeBudget      *prometheus.GaugeVec
+
 	// Refresh metrics
-	refreshCount  *prometheus.CounterVec
-	refreshErrors *prometheus.CounterVec
+	refreshCount    *prometheus.CounterVec
+	refreshErrors   *prometheus.CounterVec
+	refreshDeferred *prometheus.CounterVec
+	refreshDropped  *prometheus.CounterVec
+	forceCoalesced  *prometheus.CounterVec
+	forceThrottled  *prometheus.CounterVec
+	pricePublishes  *prometheus.CounterVec
+
+	// Streaming metrics
+	streamClients prometheus.Gauge
+	streamDropped prometheus.Counter
+
+	// Asset health metrics
+	assetHealth       *prometheus.GaugeVec
+	healthTransitions *prometheus.CounterVec
 
 	// Asset metrics
 	assetAccessCount *prometheus.CounterVec
+	staleResponses   *prometheus.CounterVec
+	tierTransitions  *prometheus.CounterVec
+
+	// Coordination metrics
+	leaderStatus      prometheus.Gauge
+	leaderTransitions *prometheus.CounterVec
+	fencedWrites      *prometheus.CounterVec
+	clusterMembers    prometheus.Gauge
+	ownedAssets       prometheus.Gauge
 }
 
 // NewMetricsService creates a new metrics service
@@ -49,6 +80,20 @@ func NewMetricsService() *MetricsService {
 			},
 			[]string{"endpoint", "status"},
 		),
+		grpcRequests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_grpc_requests_total",
+				Help: "Total number of gRPC requests",
+			},
+			[]string{"method", "code"},
+		),
+		deprecatedRequests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_api_deprecated_requests_total",
+				Help: "Total number of requests to deprecated unversioned routes",
+			},
+			[]string{"route"},
+		),
 		apiRequestDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "price_api_request_duration_seconds",
@@ -71,6 +116,26 @@ func NewMetricsService() *MetricsService {
 				Help: "Total number of cache misses",
 			},
 		),
+		l1Cache: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_l1_cache_events_total",
+				Help: "In-process cache events (hit, miss, eviction, invalidation)",
+			},
+			[]string{"event"},
+		),
+		nearExpiryHits: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "price_cache_near_expiry_hits_total",
+				Help: "Total number of cache hits on entries about to expire",
+			},
+		),
+		negativeCache: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_negative_cache_events_total",
+				Help: "Negative cache events for unavailable assets (hit, store)",
+			},
+			[]string{"event"},
+		),
 
 		// Exchange metrics
 		exchangeRequests: promauto.NewCounterVec(
@@ -105,6 +170,22 @@ func NewMetricsService() *MetricsService {
 			[]string{"exchange"},
 		),
 
+		// Rate limit metrics
+		exchangeRateLimited: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_exchange_rate_limited_total",
+				Help: "Total number of exchange requests rejected by the rate limiter",
+			},
+			[]string{"exchange", "priority"},
+		),
+		exchangeBudget: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "price_exchange_budget_tokens",
+				Help: "Tokens currently available in the exchange request budget",
+			},
+			[]string{"exchange"},
+		),
+
 		// Refresh metrics
 		refreshCount: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -120,6 +201,73 @@ func NewMetricsService() *MetricsService {
 			},
 			[]string{"tier"},
 		),
+		refreshDeferred: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_refresh_deferred_total",
+				Help: "Total number of refreshes postponed because the exchange budget was exhausted",
+			},
+			[]string{"tier"},
+		),
+		refreshDropped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_refresh_dropped_total",
+				Help: "Total number of refreshes skipped because the exchange budget stayed exhausted",
+			},
+			[]string{"tier"},
+		),
+
+		forceCoalesced: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_force_refresh_coalesced_total",
+				Help: "Total number of forced refreshes that joined one already in flight for the same asset",
+			},
+			[]string{"tier"},
+		),
+		forceThrottled: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_force_refresh_throttled_total",
+				Help: "Total number of forced refreshes skipped because the asset was refreshed moments ago",
+			},
+			[]string{"tier"},
+		),
+
+		pricePublishes: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_update_publish_total",
+				Help: "Total number of refreshed prices published to Redis pub/sub and stream, by result",
+			},
+			[]string{"result"},
+		),
+
+		// Streaming metrics
+		streamClients: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "price_stream_clients",
+				Help: "Number of clients subscribed to live price updates",
+			},
+		),
+		streamDropped: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "price_stream_dropped_total",
+				Help: "Total number of price updates replaced by a newer one before a slow client took them",
+			},
+		),
+
+		// Asset health metrics
+		assetHealth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "price_asset_health",
+				Help: "Number of assets in each health state (healthy, degraded, failing, unavailable)",
+			},
+			[]string{"state"},
+		),
+		healthTransitions: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_asset_health_transitions_total",
+				Help: "Total number of assets changing health state",
+			},
+			[]string{"from", "to"},
+		),
 
 		// Asset metrics
 		assetAccessCount: promauto.NewCounterVec(
@@ -129,6 +277,54 @@ func NewMetricsService() *MetricsService {
 			},
 			[]string{"asset", "tier"},
 		),
+		staleResponses: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_stale_responses_total",
+				Help: "Total number of prices served older than their tier's maximum data age",
+			},
+			[]string{"tier"},
+		),
+		tierTransitions: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_tier_transitions_total",
+				Help: "Total number of assets moved between refresh tiers",
+			},
+			[]string{"from", "to"},
+		),
+
+		// Coordination metrics
+		leaderStatus: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "price_leader_status",
+				Help: "Whether this replica is the refresh leader (1=leader, 0=follower)",
+			},
+		),
+		leaderTransitions: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_leader_transitions_total",
+				Help: "Total number of leadership changes on this replica",
+			},
+			[]string{"event"},
+		),
+		fencedWrites: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "price_fenced_writes_total",
+				Help: "Total number of refresh writes rejected because a newer leader had written the asset",
+			},
+			[]string{"target"},
+		),
+		clusterMembers: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "price_cluster_members",
+				Help: "Number of live replicas sharing refresh work",
+			},
+		),
+		ownedAssets: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "price_owned_assets",
+				Help: "Number of assets this replica is responsible for refreshing",
+			},
+		),
 	}
 
 	return m
@@ -150,6 +346,21 @@ func (m *MetricsService) RecordCacheMiss() {
 	m.cacheMisses.Inc()
 }
 
+// RecordL1Cache records an in-process cache event
+func (m *MetricsService) RecordL1Cache(event string) {
+	m.l1Cache.WithLabelValues(event).Inc()
+}
+
+// RecordCacheNearExpiryHit records a cache hit on an entry about to expire
+func (m *MetricsService) RecordCacheNearExpiryHit() {
+	m.nearExpiryHits.Inc()
+}
+
+// RecordNegativeCache records a negative cache event
+func (m *MetricsService) RecordNegativeCache(event string) {
+	m.negativeCache.WithLabelValues(event).Inc()
+}
+
 // GetCacheHitRate returns the cache hit rate as a percentage
 func (m *MetricsService) GetCacheHitRate() float64 {
 	m.cacheMutex.Lock()
@@ -171,6 +382,16 @@ func (m *MetricsService) RecordAPIRequest(endpoint string, status int) {
 	m.apiRequests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
 }
 
+// RecordGRPCRequest records a gRPC request and its status code
+func (m *MetricsService) RecordGRPCRequest(method, code string) {
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+}
+
+// RecordDeprecatedRequest records a request to a deprecated route
+func (m *MetricsService) RecordDeprecatedRequest(route string) {
+	m.deprecatedRequests.WithLabelValues(route).Inc()
+}
+
 // ObserveAPIRequestDuration records the duration of an API request
 func (m *MetricsService) ObserveAPIRequestDuration(endpoint string, duration time.Duration) {
 	m.apiRequestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
@@ -197,6 +418,16 @@ func (m *MetricsService) RecordCircuitBreakerState(exchange string, state int) {
 	m.circuitBreakerState.WithLabelValues(exchange).Set(float64(state))
 }
 
+// RecordExchangeRateLimited records a request rejected by an exchange's rate limiter
+func (m *MetricsService) RecordExchangeRateLimited(exchange, priority string) {
+	m.exchangeRateLimited.WithLabelValues(exchange, priority).Inc()
+}
+
+// RecordExchangeBudget records the tokens left in an exchange's request budget
+func (m *MetricsService) RecordExchangeBudget(exchange string, tokens float64) {
+	m.exchangeBudget.WithLabelValues(exchange).Set(tokens)
+}
+
 // RecordRefresh records a price refresh
 // tier: "hot", "medium", "cold"
 // triggerType: "auto", "manual", "force"
@@ -209,7 +440,91 @@ func (m *MetricsService) RecordRefreshError(tier string) {
 	m.refreshErrors.WithLabelValues(tier).Inc()
 }
 
+// RecordRefreshDeferred records a refresh postponed for lack of exchange budget
+func (m *MetricsService) RecordRefreshDeferred(tier string) {
+	m.refreshDeferred.WithLabelValues(tier).Inc()
+}
+
+// RecordRefreshDropped records a refresh skipped for lack of exchange budget
+func (m *MetricsService) RecordRefreshDropped(tier string) {
+	m.refreshDropped.WithLabelValues(tier).Inc()
+}
+
+// RecordForceRefreshCoalesced records a forced refresh that shared an in-flight fetch
+func (m *MetricsService) RecordForceRefreshCoalesced(tier string) {
+	m.forceCoalesced.WithLabelValues(tier).Inc()
+}
+
+// RecordForceRefreshThrottled records a forced refresh skipped by the minimum interval
+func (m *MetricsService) RecordForceRefreshThrottled(tier string) {
+	m.forceThrottled.WithLabelValues(tier).Inc()
+}
+
+// RecordPricePublish records the result of publishing a price update
+func (m *MetricsService) RecordPricePublish(result string) {
+	m.pricePublishes.WithLabelValues(result).Inc()
+}
+
+// RecordStreamClients records the number of live price subscribers
+func (m *MetricsService) RecordStreamClients(count int) {
+	m.streamClients.Set(float64(count))
+}
+
+// RecordStreamDropped records an update a slow subscriber never received
+func (m *MetricsService) RecordStreamDropped() {
+	m.streamDropped.Inc()
+}
+
+// RecordAssetHealth records how many assets are in each health state
+func (m *MetricsService) RecordAssetHealth(counts map[string]int) {
+	for state, count := range counts {
+		m.assetHealth.WithLabelValues(state).Set(float64(count))
+	}
+}
+
+// RecordHealthTransition records an asset changing health state
+func (m *MetricsService) RecordHealthTransition(from, to string) {
+	m.healthTransitions.WithLabelValues(from, to).Inc()
+}
+
 // RecordAssetAccess records an access to an asset
 func (m *MetricsService) RecordAssetAccess(asset, tier string) {
 	m.assetAccessCount.WithLabelValues(asset, tier).Inc()
 }
+
+// RecordStaleResponse records a price served past its tier's maximum data age
+func (m *MetricsService) RecordStaleResponse(tier string) {
+	m.staleResponses.WithLabelValues(tier).Inc()
+}
+
+// RecordTierTransition records an asset moving between refresh tiers
+func (m *MetricsService) RecordTierTransition(from, to string) {
+	m.tierTransitions.WithLabelValues(from, to).Inc()
+}
+
+// RecordLeaderStatus records this replica gaining or losing refresh leadership
+func (m *MetricsService) RecordLeaderStatus(isLeader bool) {
+	if isLeader {
+		m.leaderStatus.Set(1)
+		m.leaderTransitions.WithLabelValues("elected").Inc()
+		return
+	}
+	m.leaderStatus.Set(0)
+	m.leaderTransitions.WithLabelValues("revoked").Inc()
+}
+
+// RecordFencedWrite records a write rejected by the cache or storage because
+// it carried an older fencing token than the asset's last writer
+func (m *MetricsService) RecordFencedWrite(target string) {
+	m.fencedWrites.WithLabelValues(target).Inc()
+}
+
+// RecordClusterMembers records the number of live replicas in the refresh cluster
+func (m *MetricsService) RecordClusterMembers(count int) {
+	m.clusterMembers.Set(float64(count))
+}
+
+// RecordOwnedAssets records how many assets this replica refreshes
+func (m *MetricsService) RecordOwnedAssets(count int) {
+	m.ownedAssets.Set(float64(count))
+}
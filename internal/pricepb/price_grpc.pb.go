@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: price.proto
+
+package pricepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PriceService_GetPrice_FullMethodName        = "/price.v1.PriceService/GetPrice"
+	PriceService_BatchGetPrices_FullMethodName  = "/price.v1.PriceService/BatchGetPrices"
+	PriceService_RefreshPrice_FullMethodName    = "/price.v1.PriceService/RefreshPrice"
+	PriceService_GetHistory_FullMethodName      = "/price.v1.PriceService/GetHistory"
+	PriceService_SubscribePrices_FullMethodName = "/price.v1.PriceService/SubscribePrices"
+)
+
+// PriceServiceClient is the client API for PriceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PriceServiceClient interface {
+	// GetPrice returns the current price like GET /prices/{asset}: from the
+	// cache, then storage, refreshing it when missing or too old
+	GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*Price, error)
+	// BatchGetPrices returns several prices like GET /prices?assets= without
+	// forcing refreshes
+	BatchGetPrices(ctx context.Context, in *BatchGetPricesRequest, opts ...grpc.CallOption) (*BatchGetPricesResponse, error)
+	// RefreshPrice forces a refresh like POST /refresh/{asset}
+	RefreshPrice(ctx context.Context, in *RefreshPriceRequest, opts ...grpc.CallOption) (*RefreshPriceResponse, error)
+	// GetHistory returns the recent updates kept in the asset's Redis history
+	// stream, newest first
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// SubscribePrices streams every refreshed price of the requested assets
+	// like GET /stream/prices; slow clients only get the newest update of
+	// each asset
+	SubscribePrices(ctx context.Context, in *SubscribePricesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PriceUpdate], error)
+}
+
+type priceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPriceServiceClient(cc grpc.ClientConnInterface) PriceServiceClient {
+	return &priceServiceClient{cc}
+}
+
+func (c *priceServiceClient) GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*Price, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Price)
+	err := c.cc.Invoke(ctx, PriceService_GetPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceServiceClient) BatchGetPrices(ctx context.Context, in *BatchGetPricesRequest, opts ...grpc.CallOption) (*BatchGetPricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetPricesResponse)
+	err := c.cc.Invoke(ctx, PriceService_BatchGetPrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceServiceClient) RefreshPrice(ctx context.Context, in *RefreshPriceRequest, opts ...grpc.CallOption) (*RefreshPriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshPriceResponse)
+	err := c.cc.Invoke(ctx, PriceService_RefreshPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, PriceService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceServiceClient) SubscribePrices(ctx context.Context, in *SubscribePricesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PriceUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PriceService_ServiceDesc.Streams[0], PriceService_SubscribePrices_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribePricesRequest, PriceUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PriceService_SubscribePricesClient = grpc.ServerStreamingClient[PriceUpdate]
+
+// PriceServiceServer is the server API for PriceService service.
+// All implementations must embed UnimplementedPriceServiceServer
+// for forward compatibility.
+type PriceServiceServer interface {
+	// GetPrice returns the current price like GET /prices/{asset}: from the
+	// cache, then storage, refreshing it when missing or too old
+	GetPrice(context.Context, *GetPriceRequest) (*Price, error)
+	// BatchGetPrices returns several prices like GET /prices?assets= without
+	// forcing refreshes
+	BatchGetPrices(context.Context, *BatchGetPricesRequest) (*BatchGetPricesResponse, error)
+	// RefreshPrice forces a refresh like POST /refresh/{asset}
+	RefreshPrice(context.Context, *RefreshPriceRequest) (*RefreshPriceResponse, error)
+	// GetHistory returns the recent updates kept in the asset's Redis history
+	// stream, newest first
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// SubscribePrices streams every refreshed price of the requested assets
+	// like GET /stream/prices; slow clients only get the newest update of
+	// each asset
+	SubscribePrices(*SubscribePricesRequest, grpc.ServerStreamingServer[PriceUpdate]) error
+	mustEmbedUnimplementedPriceServiceServer()
+}
+
+// UnimplementedPriceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPriceServiceServer struct{}
+
+func (UnimplementedPriceServiceServer) GetPrice(context.Context, *GetPriceRequest) (*Price, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrice not implemented")
+}
+func (UnimplementedPriceServiceServer) BatchGetPrices(context.Context, *BatchGetPricesRequest) (*BatchGetPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetPrices not implemented")
+}
+func (UnimplementedPriceServiceServer) RefreshPrice(context.Context, *RefreshPriceRequest) (*RefreshPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshPrice not implemented")
+}
+func (UnimplementedPriceServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedPriceServiceServer) SubscribePrices(*SubscribePricesRequest, grpc.ServerStreamingServer[PriceUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePrices not implemented")
+}
+func (UnimplementedPriceServiceServer) mustEmbedUnimplementedPriceServiceServer() {}
+func (UnimplementedPriceServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafePriceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PriceServiceServer will
+// result in compilation errors.
+type UnsafePriceServiceServer interface {
+	mustEmbedUnimplementedPriceServiceServer()
+}
+
+func RegisterPriceServiceServer(s grpc.ServiceRegistrar, srv PriceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPriceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PriceService_ServiceDesc, srv)
+}
+
+func _PriceService_GetPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceServiceServer).GetPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceService_GetPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceServiceServer).GetPrice(ctx, req.(*GetPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceService_BatchGetPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceServiceServer).BatchGetPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceService_BatchGetPrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceServiceServer).BatchGetPrices(ctx, req.(*BatchGetPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceService_RefreshPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceServiceServer).RefreshPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceService_RefreshPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceServiceServer).RefreshPrice(ctx, req.(*RefreshPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceService_SubscribePrices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePricesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PriceServiceServer).SubscribePrices(m, &grpc.GenericServerStream[SubscribePricesRequest, PriceUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PriceService_SubscribePricesServer = grpc.ServerStreamingServer[PriceUpdate]
+
+// PriceService_ServiceDesc is the grpc.ServiceDesc for PriceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PriceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "price.v1.PriceService",
+	HandlerType: (*PriceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPrice",
+			Handler:    _PriceService_GetPrice_Handler,
+		},
+		{
+			MethodName: "BatchGetPrices",
+			Handler:    _PriceService_BatchGetPrices_Handler,
+		},
+		{
+			MethodName: "RefreshPrice",
+			Handler:    _PriceService_RefreshPrice_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _PriceService_GetHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePrices",
+			Handler:       _PriceService_SubscribePrices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "price.proto",
+}
@@ -0,0 +1,142 @@
+// internal/ratelimiter/rate_limiter.go
+package ratelimiter
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority represents how important a request is when the budget runs low.
+// Lower values are more important.
+type Priority int
+
+const (
+	// Critical is used for user-triggered refreshes (ForceRefresh, /refresh)
+	Critical Priority = iota
+	// High is used for hot tier refreshes
+	High
+	// Normal is used for medium tier refreshes
+	Normal
+	// Low is used for cold tier refreshes
+	Low
+)
+
+// reserveFractions holds the share of the bucket that must remain after a
+// request of each priority, so that lower priorities back off first when
+// the budget is running out.
+var reserveFractions = map[Priority]float64{
+	Critical: 0,
+	High:     0.1,
+	Normal:   0.3,
+	Low:      0.5,
+}
+
+var (
+	// ErrRateLimited is returned when no token is available for a request
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// String returns the priority name used in logs and metric labels
+func (p Priority) String() string {
+	switch p {
+	case Critical:
+		return "critical"
+	case High:
+		return "high"
+	case Normal:
+		return "normal"
+	case Low:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// RateLimiter implements a token bucket shared by all callers of an exchange
+type RateLimiter struct {
+	name       string
+	rate       float64 // tokens added per second
+	burst      float64 // bucket capacity
+	tokens     float64
+	lastRefill time.Time
+	mutex      sync.Mutex
+}
+
+// New creates a new rate limiter. A rate of zero or less disables limiting.
+func New(name string, ratePerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		name:       name,
+		rate:       ratePerSecond,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		lastRefill: time.Now(),
+	}
+}
+
+// refill adds the tokens accumulated since the last call; caller holds the mutex
+func (rl *RateLimiter) refill() {
+	now := time.Now()
+	elapsed := now.Sub(rl.lastRefill).Seconds()
+	rl.lastRefill = now
+	rl.tokens += elapsed * rl.rate
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+}
+
+// TryAcquire takes a token if one is available for the given priority.
+// Lower priorities must leave part of the bucket for higher ones.
+func (rl *RateLimiter) TryAcquire(p Priority) bool {
+	if rl.rate <= 0 {
+		return true
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.refill()
+	reserve := rl.burst * reserveFractions[p]
+	if rl.tokens-1 < reserve {
+		return false
+	}
+	rl.tokens--
+	return true
+}
+
+// Wait blocks until a token is available for the given priority or the
+// timeout expires. It returns ErrRateLimited on timeout.
+func (rl *RateLimiter) Wait(p Priority, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if rl.TryAcquire(p) {
+			return nil
+		}
+
+		// Sleep roughly until the next token is due
+		wait := time.Duration(float64(time.Second) / rl.rate)
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return ErrRateLimited
+		}
+		if wait > remaining {
+			wait = remaining
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Available returns the number of tokens currently in the bucket
+func (rl *RateLimiter) Available() float64 {
+	if rl.rate <= 0 {
+		return rl.burst
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.refill()
+	return rl.tokens
+}
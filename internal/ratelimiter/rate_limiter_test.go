@@ -0,0 +1,99 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// slowRate refills so slowly that no token comes back during a test
+const slowRate = 0.0001
+
+// drain takes tokens at priority p until the limiter refuses, returning how
+// many it got
+func drain(rl *RateLimiter, p Priority) int {
+	taken := 0
+	for rl.TryAcquire(p) {
+		taken++
+		if taken > 1000 {
+			break
+		}
+	}
+	return taken
+}
+
+func TestTryAcquireReserves(t *testing.T) {
+	// A full bucket of 10 keeps 0, 1, 3 and 5 tokens back from each priority
+	tests := []struct {
+		priority Priority
+		want     int
+	}{
+		{priority: Critical, want: 10},
+		{priority: High, want: 9},
+		{priority: Normal, want: 7},
+		{priority: Low, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.priority.String(), func(t *testing.T) {
+			rl := New("test", slowRate, 10)
+			if got := drain(rl, tt.priority); got != tt.want {
+				t.Errorf("took %d tokens, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTryAcquireLowerPrioritiesBackOffFirst(t *testing.T) {
+	// Each priority drains the bucket down to its own reserve, so what is
+	// left for it depends on the lower priorities that ran before
+	steps := []struct {
+		priority Priority
+		want     int
+	}{
+		{priority: Low, want: 5},
+		{priority: Low, want: 0},
+		{priority: Normal, want: 2},
+		{priority: High, want: 2},
+		{priority: Normal, want: 0},
+		{priority: Critical, want: 1},
+		{priority: Critical, want: 0},
+	}
+	rl := New("test", slowRate, 10)
+	for i, step := range steps {
+		if got := drain(rl, step.priority); got != step.want {
+			t.Errorf("step %d: %s took %d tokens, want %d", i, step.priority, got, step.want)
+		}
+	}
+}
+
+func TestTryAcquireDisabled(t *testing.T) {
+	rl := New("test", 0, 1)
+	for i := 0; i < 100; i++ {
+		if !rl.TryAcquire(Low) {
+			t.Fatalf("request %d was limited with limiting disabled", i)
+		}
+	}
+	if got := rl.Available(); got != 1 {
+		t.Errorf("Available() = %v, want the burst", got)
+	}
+}
+
+func TestWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		priority Priority
+		wantErr  error
+	}{
+		{name: "critical gets the refilled token", rate: 50, priority: Critical, wantErr: nil},
+		{name: "low times out behind its reserve", rate: slowRate, priority: Low, wantErr: ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := New("test", tt.rate, 2)
+			drain(rl, Critical)
+			if err := rl.Wait(tt.priority, 100*time.Millisecond); err != tt.wantErr {
+				t.Errorf("Wait() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
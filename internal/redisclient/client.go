@@ -0,0 +1,141 @@
+// internal/redisclient/client.go
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Supported Redis topologies
+const (
+	ModeStandalone = "standalone"
+	ModeCluster    = "cluster"
+	ModeSentinel   = "sentinel"
+)
+
+// Config describes how to connect to Redis
+type Config struct {
+	Mode       string   // standalone, cluster or sentinel
+	Addrs      []string // node address, cluster seed nodes or sentinel addresses
+	MasterName string   // sentinel master name
+
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int // ignored in cluster mode
+
+	TLS           bool
+	TLSCAFile     string // PEM bundle to verify the server with; system roots if empty
+	TLSServerName string
+
+	PoolSize     int // 0 uses the go-redis default of 10 per CPU
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// New creates a client for the configured topology
+func New(cfg Config) (redis.UniversalClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("no Redis address configured")
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+	}
+
+	if cfg.TLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	// Pick the client explicitly rather than letting NewUniversalClient guess
+	// from the number of addresses; a cluster may be given a single seed node
+	switch cfg.Mode {
+	case "", ModeStandalone:
+		if len(cfg.Addrs) > 1 {
+			return nil, fmt.Errorf("standalone mode takes one address, got %d", len(cfg.Addrs))
+		}
+		return redis.NewClient(opts.Simple()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(opts.Failover()), nil
+	default:
+		return nil, fmt.Errorf("unknown Redis mode %q", cfg.Mode)
+	}
+}
+
+// newTLSConfig builds the TLS settings for the connection
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.TLSServerName,
+	}
+	if cfg.TLSCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Redis CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.TLSCAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// Ping checks that Redis answers within the timeout
+func Ping(client redis.UniversalClient, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+// WaitUntilReady pings Redis every interval in the background until it
+// answers, then calls onReady. Used when the service starts while Redis is down
+func WaitUntilReady(client redis.UniversalClient, interval time.Duration, onReady func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := Ping(client, interval); err != nil {
+				log.Printf("Redis still unavailable: %v", err)
+				continue
+			}
+			log.Println("Redis connection established")
+			if onReady != nil {
+				onReady()
+			}
+			return
+		}
+	}()
+}
@@ -0,0 +1,337 @@
+// internal/refresher/control.go
+package refresher
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"real-time-price-aggregator/internal/fetcher"
+	"real-time-price-aggregator/internal/symbols"
+)
+
+// ErrUnknownTier is returned when pinning an asset to a tier that isn't configured
+var ErrUnknownTier = errors.New("unknown tier")
+
+// assetState holds the runtime state of a single asset's refresh loop
+type assetState struct {
+	pinned      bool // adaptive tiering leaves pinned assets alone
+	paused      bool
+	lastRefresh time.Time
+	lastError   string
+	lastErrorAt time.Time
+	nextRefresh time.Time
+
+	// Failure streak used for backoff and health
+	failures     int
+	failingSince time.Time
+	health       Health
+}
+
+// AssetStatus is a snapshot of an asset's refresh state for the admin API
+type AssetStatus struct {
+	Asset        string
+	Tier         string
+	Pinned       bool
+	Paused       bool
+	LastRefresh  time.Time
+	LastError    string
+	LastErrorAt  time.Time
+	NextRefresh  time.Time
+	Health       Health
+	Failures     int
+	FailingSince time.Time // start of the current failure streak; zero when healthy
+}
+
+// state returns the state entry for an asset; caller holds the mutex
+func (r *Refresher) state(asset string) *assetState {
+	st, ok := r.states[asset]
+	if !ok {
+		st = &assetState{health: HealthHealthy}
+		r.states[asset] = st
+	}
+	return st
+}
+
+// isSupported reports whether the asset is in the symbol list; caller holds the mutex
+func (r *Refresher) isSupported(asset string) bool {
+	for _, a := range r.supportedList {
+		if a == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// recordResult stores the outcome of a refresh attempt
+func (r *Refresher) recordResult(asset string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// The asset may have been delisted while its refresh was in flight
+	if _, ok := r.assetTiers[asset]; !ok {
+		return
+	}
+
+	st := r.state(asset)
+	r.updateHealth(asset, st, err)
+	if err != nil {
+		st.lastError = err.Error()
+		st.lastErrorAt = time.Now()
+		return
+	}
+	st.lastRefresh = time.Now()
+	st.lastError = ""
+}
+
+// scheduleNext records when the asset's loop will refresh it next
+func (r *Refresher) scheduleNext(asset string, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.assetTiers[asset]; ok {
+		r.state(asset).nextRefresh = at
+	}
+}
+
+// snapshot builds the status of an asset; caller holds the mutex
+func (r *Refresher) snapshot(asset string) AssetStatus {
+	st := r.state(asset)
+	status := AssetStatus{
+		Asset:        asset,
+		Tier:         r.tiers.Lookup(r.assetTiers[asset]).Name,
+		Pinned:       st.pinned,
+		Paused:       st.paused,
+		LastRefresh:  st.lastRefresh,
+		LastError:    st.lastError,
+		LastErrorAt:  st.lastErrorAt,
+		Health:       st.health,
+		Failures:     st.failures,
+		FailingSince: st.failingSince,
+	}
+	// Only a running loop has a next refresh
+	if _, running := r.stopChans[asset]; running {
+		status.NextRefresh = st.nextRefresh
+	}
+	return status
+}
+
+// GetAssetStatus returns the refresh state of a single asset
+func (r *Refresher) GetAssetStatus(asset string) (AssetStatus, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.isSupported(asset) {
+		return AssetStatus{}, fetcher.ErrAssetNotSupported
+	}
+	return r.snapshot(asset), nil
+}
+
+// GetAllAssetStatuses returns the refresh state of every asset, sorted by symbol
+func (r *Refresher) GetAllAssetStatuses() []AssetStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	statuses := make([]AssetStatus, 0, len(r.supportedList))
+	for _, asset := range r.supportedList {
+		statuses = append(statuses, r.snapshot(asset))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Asset < statuses[j].Asset
+	})
+	return statuses
+}
+
+// PinTier moves an asset to the given tier and keeps it there until unpinned
+func (r *Refresher) PinTier(asset, tierName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.isSupported(asset) {
+		return fetcher.ErrAssetNotSupported
+	}
+	if _, ok := r.tiers.Get(tierName); !ok {
+		return ErrUnknownTier
+	}
+
+	r.state(asset).pinned = true
+	current := r.assetTiers[asset]
+	if current == tierName {
+		log.Printf("Pinned %s to %s tier", asset, tierName)
+		return nil
+	}
+
+	r.assetTiers[asset] = tierName
+	r.metrics.RecordTierTransition(current, tierName)
+	log.Printf("Pinned %s to %s tier (was %s)", asset, tierName, current)
+
+	if r.isRunning {
+		r.restartLoop(asset)
+	}
+	return nil
+}
+
+// UnpinTier hands an asset back to adaptive tiering
+func (r *Refresher) UnpinTier(asset string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.isSupported(asset) {
+		return fetcher.ErrAssetNotSupported
+	}
+	r.state(asset).pinned = false
+	log.Printf("Unpinned %s from %s tier", asset, r.assetTiers[asset])
+	return nil
+}
+
+// Pause stops an asset's refresh loop until Resume is called
+func (r *Refresher) Pause(asset string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.isSupported(asset) {
+		return fetcher.ErrAssetNotSupported
+	}
+
+	r.state(asset).paused = true
+	if stop, ok := r.stopChans[asset]; ok {
+		close(stop)
+		delete(r.stopChans, asset)
+	}
+	log.Printf("Paused refresh for %s", asset)
+	return nil
+}
+
+// Resume restarts an asset's refresh loop after Pause
+func (r *Refresher) Resume(asset string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.isSupported(asset) {
+		return fetcher.ErrAssetNotSupported
+	}
+
+	r.state(asset).paused = false
+	if r.isRunning {
+		r.restartLoop(asset)
+	}
+	log.Printf("Resumed refresh for %s", asset)
+	return nil
+}
+
+// AddAssets registers newly listed assets, assigns their tiers and starts
+// their refresh loops if the service is running
+func (r *Refresher) AddAssets(assets []symbols.Symbol) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, s := range assets {
+		asset := s.Symbol
+		if r.isSupported(asset) {
+			continue
+		}
+		// New listings are ranked after the existing ones; adaptive tiering promotes them
+		r.supportedList = append(r.supportedList, asset)
+		r.assetTiers[asset] = r.tiers.ForRank(len(r.supportedList) - 1).Name
+		if _, ok := r.tiers.Get(s.Tier); ok {
+			r.assetTiers[asset] = s.Tier
+			r.state(asset).pinned = true
+		}
+		log.Printf("Listed %s in %s tier", asset, r.assetTiers[asset])
+
+		if r.isRunning {
+			r.restartLoop(asset)
+		}
+	}
+	r.publishHealth()
+}
+
+// UpdateAssets applies tier overrides from the symbol registry; a symbol
+// without an override is handed back to adaptive tiering
+func (r *Refresher) UpdateAssets(assets []symbols.Symbol) {
+	for _, s := range assets {
+		var err error
+		if s.Tier != "" {
+			err = r.PinTier(s.Symbol, s.Tier)
+		} else {
+			err = r.UnpinTier(s.Symbol)
+		}
+		if err != nil {
+			log.Printf("Failed to apply tier override for %s: %v", s.Symbol, err)
+		}
+	}
+}
+
+// RemoveAssets stops refreshing delisted assets and forgets their state
+func (r *Refresher) RemoveAssets(assets []string) {
+	removed := make(map[string]bool, len(assets))
+	for _, asset := range assets {
+		removed[asset] = true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	remaining := make([]string, 0, len(r.supportedList))
+	for _, asset := range r.supportedList {
+		if !removed[asset] {
+			remaining = append(remaining, asset)
+			continue
+		}
+		if stop, ok := r.stopChans[asset]; ok {
+			close(stop)
+			delete(r.stopChans, asset)
+		}
+		delete(r.assetTiers, asset)
+		delete(r.states, asset)
+		log.Printf("Delisted %s, refresh stopped", asset)
+	}
+	r.supportedList = remaining
+	r.publishHealth()
+
+	r.accessMutex.Lock()
+	for asset := range removed {
+		delete(r.accessStats, asset)
+	}
+	r.accessMutex.Unlock()
+}
+
+// SetOwnership limits refresh loops to the assets for which owns returns true
+// Call Rebalance after ownership changes to start and stop loops accordingly
+func (r *Refresher) SetOwnership(owns func(asset string) bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.owns = owns
+}
+
+// Rebalance starts loops for newly owned assets and stops loops for assets
+// that moved to another replica
+func (r *Refresher) Rebalance() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	owned, started, stopped := 0, 0, 0
+	for _, asset := range r.supportedList {
+		ownsAsset := r.owns == nil || r.owns(asset)
+		if ownsAsset {
+			owned++
+		}
+		if !r.isRunning {
+			continue
+		}
+
+		_, running := r.stopChans[asset]
+		switch {
+		case ownsAsset && !running && !r.state(asset).paused:
+			r.restartLoop(asset)
+			started++
+		case !ownsAsset && running:
+			close(r.stopChans[asset])
+			delete(r.stopChans, asset)
+			stopped++
+		}
+	}
+
+	r.metrics.RecordOwnedAssets(owned)
+	log.Printf("Rebalanced refresh ownership: %d owned, %d started, %d stopped", owned, started, stopped)
+}
@@ -0,0 +1,153 @@
+// internal/refresher/health.go
+package refresher
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"real-time-price-aggregator/internal/fetcher"
+	"real-time-price-aggregator/internal/tiers"
+)
+
+// Health describes how reliably an asset's price is being refreshed
+type Health string
+
+const (
+	// HealthHealthy means the last refresh succeeded
+	HealthHealthy Health = "healthy"
+	// HealthDegraded means recent refreshes failed but the asset is still retried often
+	HealthDegraded Health = "degraded"
+	// HealthFailing means refreshes keep failing and the asset is backed off
+	HealthFailing Health = "failing"
+	// HealthUnavailable means refreshes have failed for so long that the last
+	// known price should no longer be served as current
+	HealthUnavailable Health = "unavailable"
+)
+
+// healthStates lists every state, for metrics
+var healthStates = []Health{HealthHealthy, HealthDegraded, HealthFailing, HealthUnavailable}
+
+// HealthPolicy controls refresh backoff and health transitions
+type HealthPolicy struct {
+	// MaxBackoff caps the delay between retries of a failing asset; the delay
+	// starts at the tier's refresh interval and doubles with every failure
+	MaxBackoff time.Duration
+	// FailingAfter is the number of consecutive failures after which a
+	// degraded asset is considered failing
+	FailingAfter int
+	// UnavailableAfter is how long an asset may keep failing before it is
+	// marked unavailable
+	UnavailableAfter time.Duration
+}
+
+// DefaultHealthPolicy returns the policy used unless SetHealthPolicy is called
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		MaxBackoff:       5 * time.Minute,
+		FailingAfter:     3,
+		UnavailableAfter: 5 * time.Minute,
+	}
+}
+
+// SetHealthPolicy replaces the backoff and health policy; call it before Start
+func (r *Refresher) SetHealthPolicy(p HealthPolicy) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.health = p
+}
+
+// GetAssetHealth returns the health of an asset; unknown assets are healthy
+func (r *Refresher) GetAssetHealth(asset string) Health {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	st, ok := r.states[asset]
+	if !ok {
+		return HealthHealthy
+	}
+	return st.health
+}
+
+// backoffDelay returns the wait before the next refresh after the given
+// number of consecutive failures
+func backoffDelay(interval time.Duration, failures int, max time.Duration) time.Duration {
+	if interval >= max {
+		return interval
+	}
+	delay := interval
+	for i := 0; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// nextDelay returns how long the asset's loop should wait before refreshing again
+func (r *Refresher) nextDelay(asset string, tier tiers.Tier) time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	failures := 0
+	if st, ok := r.states[asset]; ok {
+		failures = st.failures
+	}
+	return backoffDelay(tier.RefreshInterval.Duration, failures, r.health.MaxBackoff)
+}
+
+// updateHealth applies a refresh outcome to the asset's failure streak and
+// health state; caller holds the mutex
+// Running out of exchange budget says nothing about the asset, so it is ignored
+func (r *Refresher) updateHealth(asset string, st *assetState, err error) {
+	if errors.Is(err, fetcher.ErrRateLimited) {
+		return
+	}
+
+	now := time.Now()
+	if err == nil {
+		st.failures = 0
+		st.failingSince = time.Time{}
+	} else {
+		if st.failures == 0 {
+			st.failingSince = now
+		}
+		st.failures++
+	}
+
+	health := HealthHealthy
+	switch {
+	case st.failures == 0:
+	case r.health.UnavailableAfter > 0 && now.Sub(st.failingSince) >= r.health.UnavailableAfter:
+		health = HealthUnavailable
+	case st.failures >= r.health.FailingAfter:
+		health = HealthFailing
+	default:
+		health = HealthDegraded
+	}
+	if health == st.health {
+		return
+	}
+
+	log.Printf("Asset %s is now %s (was %s, %d consecutive failures)", asset, health, st.health, st.failures)
+	r.metrics.RecordHealthTransition(string(st.health), string(health))
+	st.health = health
+	r.publishHealth()
+}
+
+// publishHealth exports the number of assets in each health state; caller holds the mutex
+func (r *Refresher) publishHealth() {
+	counts := make(map[string]int, len(healthStates))
+	for _, h := range healthStates {
+		counts[string(h)] = 0
+	}
+	for _, asset := range r.supportedList {
+		health := HealthHealthy
+		if st, ok := r.states[asset]; ok {
+			health = st.health
+		}
+		counts[string(health)]++
+	}
+	r.metrics.RecordAssetHealth(counts)
+}
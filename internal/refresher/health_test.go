@@ -0,0 +1,32 @@
+package refresher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		max      time.Duration
+		want     time.Duration
+	}{
+		{name: "healthy", interval: 5 * time.Second, failures: 0, max: 5 * time.Minute, want: 5 * time.Second},
+		{name: "one failure", interval: 5 * time.Second, failures: 1, max: 5 * time.Minute, want: 10 * time.Second},
+		{name: "doubles per failure", interval: 5 * time.Second, failures: 4, max: 5 * time.Minute, want: 80 * time.Second},
+		{name: "capped", interval: 5 * time.Second, failures: 7, max: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "many failures stay capped", interval: 5 * time.Second, failures: 1000, max: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "interval at the cap", interval: 5 * time.Minute, failures: 3, max: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "interval above the cap", interval: 10 * time.Minute, failures: 3, max: 5 * time.Minute, want: 10 * time.Minute},
+		{name: "zero cap disables backoff", interval: 30 * time.Second, failures: 3, max: 0, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoffDelay(tt.interval, tt.failures, tt.max); got != tt.want {
+				t.Errorf("backoffDelay(%v, %d, %v) = %v, want %v", tt.interval, tt.failures, tt.max, got, tt.want)
+			}
+		})
+	}
+}
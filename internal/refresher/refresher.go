@@ -2,40 +2,37 @@
 package refresher
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"real-time-price-aggregator/internal/cache"
+	"real-time-price-aggregator/internal/events"
 	"real-time-price-aggregator/internal/fetcher"
 	"real-time-price-aggregator/internal/metrics"
 	"real-time-price-aggregator/internal/storage"
+	"real-time-price-aggregator/internal/tiers"
+	"real-time-price-aggregator/internal/types"
 
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// AssetTier represents the refresh frequency tier of an asset
-type AssetTier int
+// defaultMinForceInterval is how recent a refresh must be for ForceRefresh to skip the fetch
+const defaultMinForceInterval = time.Second
 
-const (
-	// HotTier assets refresh every 5 seconds
-	HotTier AssetTier = iota
-	// MediumTier assets refresh every 30 seconds
-	MediumTier
-	// ColdTier assets refresh every 5 minutes
-	ColdTier
-)
+// maxDeferrals is how many times a rate-limited refresh is retried before it is dropped
+const maxDeferrals = 3
 
-// RefreshInterval returns the time.Duration for a given tier
-func (t AssetTier) RefreshInterval() time.Duration {
-	switch t {
-	case HotTier:
-		return 5 * time.Second
-	case MediumTier:
-		return 30 * time.Second
-	case ColdTier:
-		return 5 * time.Minute
-	default:
-		return 5 * time.Minute
+// deferDelay returns how long to wait before retrying a rate-limited refresh
+func deferDelay(t tiers.Tier) time.Duration {
+	delay := t.RefreshInterval.Duration / (maxDeferrals + 1)
+	if delay > time.Second {
+		delay = time.Second
 	}
+	return delay
 }
 
 // Refresher is responsible for periodically refreshing asset prices
@@ -43,12 +40,39 @@ type Refresher struct {
 	fetcher       fetcher.Fetcher
 	cache         cache.Cache
 	storage       storage.Storage
-	assetTiers    map[string]AssetTier
+	tiers         *tiers.Config
+	assetTiers    map[string]string // asset -> tier name
 	stopChans     map[string]chan struct{}
 	mutex         sync.Mutex
 	isRunning     bool
 	supportedList []string
 	metrics       *metrics.MetricsService
+
+	// writeGuard, when set, must allow a refresh before its result is
+	// written and returns the fencing token to write it with (0 writes
+	// unfenced). Leader election hands out its term's token, so the cache
+	// and storage reject a deposed leader that hasn't noticed yet; sharding
+	// only drops results of assets the replica knows it lost
+	writeGuard func(asset string) (int64, bool)
+
+	// owns, when set, limits the refresh loops to assets this replica owns
+	owns func(asset string) bool
+
+	// publisher, when set, fans out every successful refresh
+	publisher *events.Publisher
+
+	// forceGroup coalesces concurrent ForceRefresh calls per asset
+	forceGroup       singleflight.Group
+	minForceInterval time.Duration
+
+	// Per-asset runtime state (pins, pauses, last result, health), guarded by mutex
+	states map[string]*assetState
+	health HealthPolicy
+
+	// Adaptive tiering state
+	accessStats map[string]*accessStats
+	accessMutex sync.Mutex
+	tieringStop chan struct{}
 }
 
 // NewRefresher creates a new auto-refresher instance
@@ -56,41 +80,49 @@ func NewRefresher(
 	f fetcher.Fetcher,
 	c cache.Cache,
 	s storage.Storage,
+	t *tiers.Config,
 	supportedList []string,
 	m *metrics.MetricsService,
 ) *Refresher {
 	return &Refresher{
-		fetcher:       f,
-		cache:         c,
-		storage:       s,
-		assetTiers:    make(map[string]AssetTier),
-		stopChans:     make(map[string]chan struct{}),
-		supportedList: supportedList,
-		metrics:       m,
+		fetcher:          f,
+		cache:            c,
+		storage:          s,
+		tiers:            t,
+		assetTiers:       make(map[string]string),
+		stopChans:        make(map[string]chan struct{}),
+		supportedList:    supportedList,
+		metrics:          m,
+		states:           make(map[string]*assetState),
+		health:           DefaultHealthPolicy(),
+		minForceInterval: defaultMinForceInterval,
+		accessStats:      make(map[string]*accessStats),
 	}
 }
 
-// AssignTiers assigns refresh tiers to assets based on their popularity
-// Top 20 assets are hot, next 180 are medium, the rest are cold
+// AssignTiers assigns initial refresh tiers to assets based on their popularity
+// Tiers are filled in configured order, so with the defaults the top 20 assets
+// are hot, the next 180 are medium and the rest are cold
+// Adaptive tiering adjusts these later from real access patterns
 func (r *Refresher) AssignTiers() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	// For simplicity, we'll just use the order in the supportedList to determine "popularity"
 	// In a real system, you might use trading volume or other metrics
+	counts := make(map[string]int)
 	for i, asset := range r.supportedList {
-		if i < 20 {
-			r.assetTiers[asset] = HotTier
-		} else if i < 200 {
-			r.assetTiers[asset] = MediumTier
-		} else {
-			r.assetTiers[asset] = ColdTier
-		}
+		name := r.tiers.ForRank(i).Name
+		r.assetTiers[asset] = name
+		counts[name]++
+	}
+
+	summary := make([]string, 0, len(r.tiers.Tiers))
+	for _, t := range r.tiers.Tiers {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[t.Name], t.Name))
 	}
-	log.Printf("Assigned tiers: %d hot, %d medium, %d cold",
-		min(20, len(r.supportedList)),
-		min(180, max(0, len(r.supportedList)-20)),
-		max(0, len(r.supportedList)-200))
+	log.Printf("Assigned tiers: %s", strings.Join(summary, ", "))
+	r.publishHealth()
 }
 
 // Start begins the auto-refresh processes for all assets
@@ -107,11 +139,7 @@ func (r *Refresher) Start() {
 
 	// Start a refresh goroutine for each asset
 	for _, asset := range r.supportedList {
-		tier := r.assetTiers[asset]
-		stop := make(chan struct{})
-		r.stopChans[asset] = stop
-
-		go r.refreshLoop(asset, tier, stop)
+		r.restartLoop(asset)
 	}
 }
 
@@ -125,6 +153,7 @@ func (r *Refresher) Stop() {
 	}
 
 	log.Println("Stopping auto-refresh service")
+	r.stopAdaptiveTiering()
 
 	// Signal all refresh goroutines to stop
 	for asset, stop := range r.stopChans {
@@ -136,141 +165,251 @@ func (r *Refresher) Stop() {
 }
 
 // refreshLoop periodically refreshes the price for a single asset
-func (r *Refresher) refreshLoop(asset string, tier AssetTier, stop <-chan struct{}) {
-	ticker := time.NewTicker(tier.RefreshInterval())
-	defer ticker.Stop()
+// Failing assets are retried with exponential backoff instead of every interval
+func (r *Refresher) refreshLoop(asset string, tier tiers.Tier, stop <-chan struct{}) {
+	for {
+		r.refreshWithBudget(asset, tier, stop)
 
-	// Initial refresh
-	r.refreshAsset(asset)
+		delay := r.nextDelay(asset, tier)
+		r.scheduleNext(asset, time.Now().Add(delay))
 
-	for {
+		timer := time.NewTimer(delay)
 		select {
-		case <-ticker.C:
-			r.refreshAsset(asset)
+		case <-timer.C:
 		case <-stop:
+			timer.Stop()
 			return
 		}
 	}
 }
 
-// refreshAsset fetches the latest price for an asset and updates cache and storage
-func (r *Refresher) refreshAsset(asset string) {
-	// acquire lock to prevent concurrent access
-	tier := r.assetTiers[asset]
-	var tierString string
-	switch tier {
-	case HotTier:
-		tierString = "hot"
-	case MediumTier:
-		tierString = "medium"
-	case ColdTier:
-		tierString = "cold"
+// refreshWithBudget refreshes an asset, deferring a few times when the exchange
+// budget is exhausted and dropping the refresh until the next tick after that
+func (r *Refresher) refreshWithBudget(asset string, tier tiers.Tier, stop <-chan struct{}) {
+	for attempt := 0; ; attempt++ {
+		err := r.refreshAsset(asset)
+		if !errors.Is(err, fetcher.ErrRateLimited) {
+			return
+		}
+
+		if attempt == maxDeferrals {
+			r.recordResult(asset, err)
+			r.metrics.RecordRefreshDropped(tier.Name)
+			log.Printf("Dropped refresh for %s: exchange budget exhausted", asset)
+			return
+		}
+		r.metrics.RecordRefreshDeferred(tier.Name)
+
+		select {
+		case <-time.After(deferDelay(tier)):
+		case <-stop:
+			return
+		}
 	}
+}
 
-	// Fetch the latest price
-	priceData, err := r.fetcher.FetchPrice(asset)
+// refreshAsset fetches the latest price for an asset and updates cache and storage
+func (r *Refresher) refreshAsset(asset string) error {
+	// tiers can change at runtime, so read under the lock
+	tier := r.GetAssetTier(asset)
+	tierString := tier.Name
+
+	// Fetch the latest price within the tier's share of the exchange budget
+	priceData, err := r.fetcher.FetchPriceWithPriority(asset, tier.BudgetPriority())
+	if errors.Is(err, fetcher.ErrRateLimited) {
+		return err
+	}
 	if err != nil {
+		r.recordResult(asset, err)
 		r.metrics.RecordRefreshError(tierString)
 		log.Printf("Failed to refresh price for %s: %v", asset, err)
-		return
-	}
-
-	// Update cache
-	if err := r.cache.Set(asset, priceData, tierString); err != nil {
-		log.Printf("Failed to update cache for %s: %v", asset, err)
+		return err
 	}
 
-	// Update storage
-	record := storage.ConvertPriceDataToRecord(priceData)
-	if err := r.storage.Save(record); err != nil {
-		log.Printf("Failed to update storage for %s: %v", asset, err)
+	// A replica that lost leadership mid-refresh must not overwrite the new leader's data
+	if !r.write(asset, priceData, tierString) {
+		return nil
 	}
+	r.publish(priceData, tierString)
 
 	// Record the refresh operation
+	r.recordResult(asset, nil)
 	r.metrics.RecordRefresh(tierString, "auto")
 	log.Printf("Refreshed price for %s: %.2f", asset, priceData.Price)
+	return nil
 }
 
 // GetAssetTier returns the refresh tier for a given asset
-func (r *Refresher) GetAssetTier(asset string) AssetTier {
+// Unknown assets get the coldest tier
+func (r *Refresher) GetAssetTier(asset string) tiers.Tier {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	return r.assetTiers[asset]
+	return r.tiers.Lookup(r.assetTiers[asset])
+}
+
+// SetWriteGuard installs a check that refreshes must pass before writing to
+// cache and storage, returning the fencing token to write with (0 for none);
+// call it before Start
+func (r *Refresher) SetWriteGuard(guard func(asset string) (token int64, ok bool)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.writeGuard = guard
+}
+
+// Tiers returns the tier configuration used by the refresher
+func (r *Refresher) Tiers() *tiers.Config {
+	return r.tiers
 }
 
 // ForceRefresh triggers an immediate refresh for a specific asset
 // This can be used when a user requests data for an infrequently updated asset
+// Concurrent calls for the same asset share a single fetch and write, and an
+// asset refreshed within the minimum force interval is not fetched again
 func (r *Refresher) ForceRefresh(asset string) error {
+	_, err := r.ForceRefreshPrice(asset)
+	return err
+}
+
+// ForceRefreshPrice is ForceRefresh returning the fetched price, so callers
+// don't depend on the cache to read it back. The price is nil when the
+// refresh was skipped because the asset was refreshed moments ago
+func (r *Refresher) ForceRefreshPrice(asset string) (*types.PriceData, error) {
 	// Check if asset is supported
-	found := false
-	for _, a := range r.supportedList {
-		if a == asset {
-			found = true
-			break
-		}
+	r.mutex.Lock()
+	found := r.isSupported(asset)
+	minInterval := r.minForceInterval
+	lastRefresh := time.Time{}
+	if st, ok := r.states[asset]; ok {
+		lastRefresh = st.lastRefresh
 	}
+	r.mutex.Unlock()
 	if !found {
-		return fetcher.ErrAssetNotSupported
+		return nil, fetcher.ErrAssetNotSupported
+	}
+
+	// tiers can change at runtime, so read under the lock
+	tierString := r.GetAssetTier(asset).Name
+
+	// The price was just refreshed, so another fetch would return the same data
+	if minInterval > 0 && !lastRefresh.IsZero() && time.Since(lastRefresh) < minInterval {
+		r.metrics.RecordForceRefreshThrottled(tierString)
+		return nil, nil
 	}
 
-	// acquire lock to prevent concurrent access
-	tier := r.assetTiers[asset]
-	var tierString string
-	switch tier {
-	case HotTier:
-		tierString = "hot"
-	case MediumTier:
-		tierString = "medium"
-	case ColdTier:
-		tierString = "cold"
+	executed := false
+	result, err, _ := r.forceGroup.Do(asset, func() (interface{}, error) {
+		executed = true
+		return r.forceRefresh(asset, tierString)
+	})
+	if !executed {
+		r.metrics.RecordForceRefreshCoalesced(tierString)
+	}
+	if err != nil {
+		return nil, err
 	}
+	priceData := *result.(*types.PriceData) // each caller gets its own copy
+	return &priceData, nil
+}
 
+// forceRefresh fetches and stores the price for ForceRefreshPrice
+func (r *Refresher) forceRefresh(asset, tierString string) (*types.PriceData, error) {
 	// Fetch the latest price
 	priceData, err := r.fetcher.FetchPrice(asset)
 	if err != nil {
+		r.recordResult(asset, err)
 		r.metrics.RecordRefreshError(tierString)
-		return err
+		return nil, err
 	}
 
-	// update cache
-	if err := r.cache.Set(asset, priceData, tierString); err != nil {
-		log.Printf("Failed to update cache for %s: %v", asset, err)
+	// Followers and non-owners answer with what they fetched but leave
+	// storing it to the replica that refreshes the asset
+	r.recordResult(asset, nil)
+	if !r.write(asset, priceData, tierString) {
+		return priceData, nil
+	}
+	r.publish(priceData, tierString)
+
+	// record the refresh operation
+	r.metrics.RecordRefresh(tierString, "force")
+	return priceData, nil
+}
+
+// write stores a refreshed price in the cache and storage if the write
+// guard allows it, with the fencing token it hands out. It returns false if
+// the price was discarded, by the guard or because a newer token had
+// written the asset
+func (r *Refresher) write(asset string, priceData *types.PriceData, tierString string) bool {
+	var token int64
+	if r.writeGuard != nil {
+		var ok bool
+		if token, ok = r.writeGuard(asset); !ok {
+			log.Printf("Discarded refresh for %s: not allowed to write", asset)
+			return false
+		}
 	}
 
-	// update storage
 	record := storage.ConvertPriceDataToRecord(priceData)
-	if err := r.storage.Save(record); err != nil {
+	if token == 0 {
+		if err := r.cache.Set(asset, priceData, tierString); err != nil {
+			log.Printf("Failed to update cache for %s: %v", asset, err)
+		}
+		if err := r.storage.Save(record); err != nil {
+			log.Printf("Failed to update storage for %s: %v", asset, err)
+		}
+		return true
+	}
+
+	// A newer leader may have written the asset already; stop at the first
+	// write that says so. A failed write says nothing, so carry on
+	if written, err := r.cache.SetFenced(asset, priceData, tierString, token); err != nil {
+		log.Printf("Failed to update cache for %s: %v", asset, err)
+	} else if !written {
+		r.metrics.RecordFencedWrite("cache")
+		log.Printf("Discarded refresh for %s: fenced off by a newer leader", asset)
+		return false
+	}
+	if written, err := r.storage.SaveFenced(record, token); err != nil {
 		log.Printf("Failed to update storage for %s: %v", asset, err)
+	} else if !written {
+		r.metrics.RecordFencedWrite("storage")
+		log.Printf("Discarded refresh for %s: fenced off by a newer leader", asset)
+		return false
 	}
+	return true
+}
 
-	// record the refresh operation
-	r.metrics.RecordRefresh(tierString, "force")
-	return nil
+// SetPublisher makes successful refreshes announce the new price; call it before Start
+func (r *Refresher) SetPublisher(p *events.Publisher) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.publisher = p
 }
 
-// min returns the smaller of x or y
-func min(x, y int) int {
-	if x < y {
-		return x
+// publish announces a refreshed price if a publisher is configured
+func (r *Refresher) publish(priceData *types.PriceData, tierString string) {
+	if r.publisher == nil {
+		return
+	}
+	if err := r.publisher.Publish(priceData, tierString); err != nil {
+		log.Printf("Failed to publish price update for %s: %v", priceData.Asset, err)
 	}
-	return y
 }
 
-// max returns the larger of x or y
-func max(x, y int) int {
-	if x > y {
-		return x
-	}
-	return y
+// SetMinForceInterval sets how long after a successful refresh ForceRefresh
+// treats the asset as fresh and skips the fetch (0 disables the check)
+func (r *Refresher) SetMinForceInterval(d time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.minForceInterval = d
 }
 
-// refresher.go
-func (r *Refresher) GetAllAssetTiers() map[string]AssetTier {
+// GetAllAssetTiers returns the tier name of every asset
+func (r *Refresher) GetAllAssetTiers() map[string]string {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	// Create a copy of the assetTiers map to avoid concurrent access issues
-	result := make(map[string]AssetTier)
+	result := make(map[string]string)
 	for k, v := range r.assetTiers {
 		result[k] = v
 	}
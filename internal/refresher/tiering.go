@@ -0,0 +1,159 @@
+// internal/refresher/tiering.go
+package refresher
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// hysteresisRounds is how many consecutive evaluations must agree on a new
+// tier before an asset is moved, so assets near a boundary don't flap
+const hysteresisRounds = 3
+
+// accessStats tracks the decayed request rate of a single asset
+type accessStats struct {
+	count       int64   // requests since the last evaluation
+	rate        float64 // decayed requests per second
+	pendingTier string
+	pendingRuns int
+}
+
+// RecordAccess counts an API read of an asset for adaptive tiering
+func (r *Refresher) RecordAccess(asset string) {
+	r.accessMutex.Lock()
+	defer r.accessMutex.Unlock()
+
+	stats, ok := r.accessStats[asset]
+	if !ok {
+		stats = &accessStats{}
+		r.accessStats[asset] = stats
+	}
+	stats.count++
+}
+
+// StartAdaptiveTiering periodically re-ranks assets by their decayed request
+// rate and moves them between tiers. halfLife controls how quickly old
+// traffic stops counting.
+func (r *Refresher) StartAdaptiveTiering(interval, halfLife time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.tieringStop != nil || interval <= 0 {
+		return
+	}
+
+	log.Printf("Starting adaptive tiering (interval %v, half-life %v)", interval, halfLife)
+	stop := make(chan struct{})
+	r.tieringStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.retier(interval, halfLife)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopAdaptiveTiering stops the re-tiering job; caller holds the mutex
+func (r *Refresher) stopAdaptiveTiering() {
+	if r.tieringStop != nil {
+		close(r.tieringStop)
+		r.tieringStop = nil
+	}
+}
+
+// retier updates decayed rates and applies tier changes that have been
+// stable for hysteresisRounds evaluations
+func (r *Refresher) retier(interval, halfLife time.Duration) {
+	// Weight of the latest window in the exponentially decayed rate
+	alpha := 1 - math.Exp(-interval.Seconds()/halfLife.Seconds())
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.accessMutex.Lock()
+	rates := make(map[string]float64, len(r.supportedList))
+	for _, asset := range r.supportedList {
+		stats, ok := r.accessStats[asset]
+		if !ok {
+			stats = &accessStats{}
+			r.accessStats[asset] = stats
+		}
+		current := float64(stats.count) / interval.Seconds()
+		stats.rate = alpha*current + (1-alpha)*stats.rate
+		stats.count = 0
+		rates[asset] = stats.rate
+	}
+	r.accessMutex.Unlock()
+
+	// Rank by rate; ties keep the CSV order so idle assets stay where they were assigned
+	ranked := make([]string, len(r.supportedList))
+	copy(ranked, r.supportedList)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return rates[ranked[i]] > rates[ranked[j]]
+	})
+
+	changed := 0
+	r.accessMutex.Lock()
+	for rank, asset := range ranked {
+		stats := r.accessStats[asset]
+		current := r.assetTiers[asset]
+		target := r.tiers.ForRank(rank).Name
+
+		// Tiers pinned through the admin API are not adjusted
+		if target == current || r.state(asset).pinned {
+			stats.pendingRuns = 0
+			continue
+		}
+		if target != stats.pendingTier {
+			stats.pendingTier = target
+			stats.pendingRuns = 0
+		}
+		stats.pendingRuns++
+		if stats.pendingRuns < hysteresisRounds {
+			continue
+		}
+
+		stats.pendingRuns = 0
+		r.assetTiers[asset] = target
+		r.metrics.RecordTierTransition(current, target)
+		log.Printf("Moved %s from %s to %s tier (%.3f req/s, rank %d)",
+			asset, current, target, stats.rate, rank+1)
+
+		// Re-schedule the refresh loop at the new interval
+		if r.isRunning {
+			r.restartLoop(asset)
+		}
+		changed++
+	}
+	r.accessMutex.Unlock()
+
+	if changed > 0 {
+		log.Printf("Adaptive tiering moved %d assets", changed)
+	}
+}
+
+// restartLoop replaces an asset's refresh goroutine with one using its
+// current tier; paused assets and assets owned by another replica are left
+// stopped. Caller holds the mutex
+func (r *Refresher) restartLoop(asset string) {
+	if stop, ok := r.stopChans[asset]; ok {
+		close(stop)
+		delete(r.stopChans, asset)
+	}
+	if r.state(asset).paused || (r.owns != nil && !r.owns(asset)) {
+		return
+	}
+	stop := make(chan struct{})
+	r.stopChans[asset] = stop
+	go r.refreshLoop(asset, r.tiers.Lookup(r.assetTiers[asset]), stop)
+}
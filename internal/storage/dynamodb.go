@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"errors"
 	"log"
+	"strconv"
 	"time"
 
 	"real-time-price-aggregator/internal/metrics"
@@ -16,6 +18,9 @@ import (
 // Storage interface defines data persistence operations
 type Storage interface {
 	Save(record PriceRecord) error
+	// SaveFenced saves a record like Save unless the asset was written with a
+	// newer fencing token than token, and reports whether it saved it
+	SaveFenced(record PriceRecord, token int64) (bool, error)
 	Get(asset string) (*PriceRecord, error)
 	BatchGet(assets []string) (map[string]*PriceRecord, error)
 }
@@ -28,6 +33,10 @@ type PriceRecord struct {
 	UpdatedAt int64   `dynamodbav:"updated_at"`
 }
 
+// fenceTimestamp is the sort key of each asset's fencing item, which holds
+// the newest fencing token that saved a price of the asset; reads skip it
+const fenceTimestamp = 0
+
 // DynamoDBStorage implements the Storage interface
 type DynamoDBStorage struct {
 	client     *dynamodb.DynamoDB
@@ -98,15 +107,99 @@ func (s *DynamoDBStorage) Save(record PriceRecord) error {
 	return nil
 }
 
+// SaveFenced saves a price record in a transaction with a conditional update
+// of the asset's fencing item, so it fails if a newer token has written
+func (s *DynamoDBStorage) SaveFenced(record PriceRecord, token int64) (bool, error) {
+	startTime := time.Now()
+
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		return false, err
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: &dynamodb.Update{
+				TableName: aws.String("prices"),
+				Key: map[string]*dynamodb.AttributeValue{
+					"asset":     {S: aws.String(record.Asset)},
+					"timestamp": {N: aws.String(strconv.Itoa(fenceTimestamp))},
+				},
+				UpdateExpression:    aws.String("SET fence_token = :token"),
+				ConditionExpression: aws.String("attribute_not_exists(fence_token) OR fence_token <= :token"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":token": {N: aws.String(strconv.FormatInt(token, 10))},
+				},
+			}},
+			{Put: &dynamodb.Put{
+				TableName: aws.String("prices"),
+				Item:      item,
+			}},
+		},
+		ReturnConsumedCapacity: aws.String("TOTAL"),
+	}
+
+	result, err := s.client.TransactWriteItems(input)
+	fenced := isConditionFailure(err)
+
+	// record metrics
+	if s.sysMetrics != nil {
+		duration := time.Since(startTime)
+		s.sysMetrics.RecordDynamoDBWriteLatency(duration)
+
+		units := 4.0 // fallback value: two transactional writes
+		if result != nil && len(result.ConsumedCapacity) > 0 {
+			units = 0
+			for _, c := range result.ConsumedCapacity {
+				if c.CapacityUnits != nil {
+					units += *c.CapacityUnits
+				}
+			}
+		}
+		s.sysMetrics.RecordDynamoDBWriteUnits(units)
+
+		if err != nil && !fenced {
+			s.sysMetrics.RecordDynamoDBError()
+		}
+	}
+
+	if fenced {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to save record for %s: %v", record.Asset, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// isConditionFailure reports whether a transaction was canceled because a
+// condition did not hold
+func isConditionFailure(err error) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if reason != nil && aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
 // Get retrieves the latest price record for an asset from DynamoDB
 func (s *DynamoDBStorage) Get(asset string) (*PriceRecord, error) {
 	startTime := time.Now()
 
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String("prices"),
-		KeyConditionExpression: aws.String("asset = :asset"),
+		TableName: aws.String("prices"),
+		// The fencing item sorts before every price and is not one
+		KeyConditionExpression:   aws.String("asset = :asset AND #ts > :fence"),
+		ExpressionAttributeNames: map[string]*string{"#ts": aws.String("timestamp")},
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":asset": {S: aws.String(asset)},
+			":fence": {N: aws.String(strconv.Itoa(fenceTimestamp))},
 		},
 		ScanIndexForward:       aws.Bool(false),
 		Limit:                  aws.Int64(1),
@@ -0,0 +1,140 @@
+// internal/symbols/registry.go
+package symbols
+
+import (
+	"sync"
+	"time"
+)
+
+// Registry holds the current symbol universe and the assets delisted from it
+// Disabled symbols keep their metadata but are not priced or refreshed
+// It is safe for concurrent use; Replace swaps the whole universe at once
+type Registry struct {
+	mutex    sync.RWMutex
+	symbols  map[string]Symbol
+	order    []string // every symbol, in file order
+	enabled  []string // enabled symbols, in file order
+	delisted map[string]time.Time
+}
+
+// NewRegistry creates a registry from an ordered list of symbols
+func NewRegistry(list []Symbol) *Registry {
+	reg := &Registry{delisted: make(map[string]time.Time)}
+	reg.Replace(list)
+	return reg
+}
+
+// IsSupported reports whether an asset is currently listed and enabled
+func (reg *Registry) IsSupported(asset string) bool {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	s, ok := reg.symbols[asset]
+	return ok && s.Enabled
+}
+
+// Get returns the metadata of a listed asset, enabled or not
+func (reg *Registry) Get(asset string) (Symbol, bool) {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	s, ok := reg.symbols[asset]
+	return s, ok
+}
+
+// DelistedAt returns when an asset was removed from the universe, if it was
+func (reg *Registry) DelistedAt(asset string) (time.Time, bool) {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	at, ok := reg.delisted[asset]
+	return at, ok
+}
+
+// List returns the enabled assets in file order
+func (reg *Registry) List() []string {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	result := make([]string, len(reg.enabled))
+	copy(result, reg.enabled)
+	return result
+}
+
+// All returns every listed symbol, including disabled ones, in file order
+func (reg *Registry) All() []Symbol {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	result := make([]Symbol, 0, len(reg.order))
+	for _, asset := range reg.order {
+		result = append(result, reg.symbols[asset])
+	}
+	return result
+}
+
+// Len returns the number of enabled assets
+func (reg *Registry) Len() int {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	return len(reg.enabled)
+}
+
+// Diff compares a new symbol list with the registry. added and removed are
+// enabled assets entering and leaving the universe; changed are assets that
+// stay enabled but whose tier override differs
+func (reg *Registry) Diff(list []Symbol) (added []Symbol, removed []string, changed []Symbol) {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	next := make(map[string]bool, len(list))
+	for _, s := range list {
+		if !s.Enabled {
+			continue
+		}
+		next[s.Symbol] = true
+
+		current, ok := reg.symbols[s.Symbol]
+		switch {
+		case !ok || !current.Enabled:
+			added = append(added, s)
+		case current.Tier != s.Tier:
+			changed = append(changed, s)
+		}
+	}
+	for _, asset := range reg.enabled {
+		if !next[asset] {
+			removed = append(removed, asset)
+		}
+	}
+	return added, removed, changed
+}
+
+// Replace swaps in a new symbol universe; assets that disappear from the file
+// are marked delisted
+func (reg *Registry) Replace(list []Symbol) {
+	symbols := make(map[string]Symbol, len(list))
+	order := make([]string, 0, len(list))
+	enabled := make([]string, 0, len(list))
+	for _, s := range list {
+		symbols[s.Symbol] = s
+		order = append(order, s.Symbol)
+		if s.Enabled {
+			enabled = append(enabled, s.Symbol)
+		}
+	}
+
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	now := time.Now()
+	for _, asset := range reg.order {
+		if _, ok := symbols[asset]; !ok {
+			reg.delisted[asset] = now
+		}
+	}
+	for asset := range symbols {
+		delete(reg.delisted, asset)
+	}
+
+	reg.symbols = symbols
+	reg.order = order
+	reg.enabled = enabled
+}
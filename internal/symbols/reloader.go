@@ -0,0 +1,187 @@
+// internal/symbols/reloader.go
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"real-time-price-aggregator/internal/tiers"
+)
+
+// AssetListener is notified about assets entering and leaving the universe
+// AddAssets runs before the registry starts serving new assets and
+// RemoveAssets runs after it stops serving removed ones
+type AssetListener interface {
+	AddAssets(assets []Symbol)
+	RemoveAssets(assets []string)
+	UpdateAssets(assets []Symbol)
+}
+
+// DefaultMaxRemoval is the largest fraction of enabled assets a reload may
+// remove without AllowMassRemoval
+const DefaultMaxRemoval = 0.5
+
+// ErrMassRemoval is returned for a reload that would remove all assets or
+// more than the allowed fraction of them, such as one that read a truncated
+// or half-written file
+var ErrMassRemoval = errors.New("reload would remove too many assets")
+
+// ReloadOptions control a single reload
+type ReloadOptions struct {
+	// AllowMassRemoval applies the file even if it removes more than the
+	// allowed fraction of assets, or all of them
+	AllowMassRemoval bool
+}
+
+// ReloadResult describes the changes applied by a reload
+type ReloadResult struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+	Total   int      `json:"total"`
+}
+
+// Reloader re-reads the symbols file and applies the differences
+type Reloader struct {
+	filename  string
+	registry  *Registry
+	tiers     *tiers.Config
+	listeners []AssetListener
+	mutex     sync.Mutex // serializes reloads
+	modTime   time.Time
+
+	maxRemoval float64 // largest fraction of enabled assets a reload may remove
+}
+
+// NewReloader creates a reloader for the given file and registry
+// Tier overrides in the file are validated against t
+func NewReloader(filename string, registry *Registry, t *tiers.Config, listeners ...AssetListener) *Reloader {
+	rl := &Reloader{
+		filename:  filename,
+		registry:  registry,
+		tiers:     t,
+		listeners: listeners,
+
+		maxRemoval: DefaultMaxRemoval,
+	}
+	if info, err := os.Stat(filename); err == nil {
+		rl.modTime = info.ModTime()
+	}
+	return rl
+}
+
+// SetMaxRemoval sets the largest fraction (0 to 1) of enabled assets a reload
+// may remove unless AllowMassRemoval is set; removing all of them always
+// needs it
+func (rl *Reloader) SetMaxRemoval(fraction float64) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.maxRemoval = fraction
+}
+
+// Reload reads the symbols file and applies added and removed assets
+// On error, including ErrMassRemoval, the current universe is left untouched
+func (rl *Reloader) Reload(opts ReloadOptions) (ReloadResult, error) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if info, err := os.Stat(rl.filename); err == nil {
+		rl.modTime = info.ModTime()
+	}
+
+	list, err := Load(rl.filename, rl.tiers)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+
+	added, removed, changed := rl.registry.Diff(list)
+	if current := rl.registry.Len(); !opts.AllowMassRemoval && len(removed) > 0 {
+		if len(removed) == current || float64(len(removed)) > rl.maxRemoval*float64(current) {
+			return ReloadResult{}, fmt.Errorf("%w: %d of %d enabled assets", ErrMassRemoval, len(removed), current)
+		}
+	}
+	for _, l := range rl.listeners {
+		if len(added) > 0 {
+			l.AddAssets(added)
+		}
+		if len(changed) > 0 {
+			l.UpdateAssets(changed)
+		}
+	}
+	rl.registry.Replace(list)
+	if len(removed) > 0 {
+		for _, l := range rl.listeners {
+			l.RemoveAssets(removed)
+		}
+	}
+
+	result := ReloadResult{
+		Added:   names(added),
+		Removed: removed,
+		Changed: names(changed),
+		Total:   rl.registry.Len(),
+	}
+	log.Printf("Reloaded symbols: %d added, %d removed, %d changed, %d enabled",
+		len(result.Added), len(result.Removed), len(result.Changed), result.Total)
+	return result, nil
+}
+
+// names returns the symbols of a list
+func names(list []Symbol) []string {
+	result := make([]string, 0, len(list))
+	for _, s := range list {
+		result = append(result, s.Symbol)
+	}
+	return result
+}
+
+// Watch polls the symbols file and reloads it when its modification time
+// changes; it also reloads on SIGHUP
+func (rl *Reloader) Watch(interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-hup:
+				log.Println("Received SIGHUP, reloading symbols")
+			case <-tick:
+				if !rl.changed() {
+					continue
+				}
+				log.Printf("%s changed, reloading symbols", rl.filename)
+			}
+
+			// Only an operator can confirm a mass removal, through the admin API
+			if _, err := rl.Reload(ReloadOptions{}); err != nil {
+				log.Printf("Failed to reload symbols: %v", err)
+			}
+		}
+	}()
+}
+
+// changed reports whether the file was modified since the last reload
+func (rl *Reloader) changed() bool {
+	info, err := os.Stat(rl.filename)
+	if err != nil {
+		return false
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	return !info.ModTime().Equal(rl.modTime)
+}
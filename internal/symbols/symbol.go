@@ -0,0 +1,183 @@
+// internal/symbols/symbol.go
+package symbols
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"real-time-price-aggregator/internal/tiers"
+)
+
+// Default metadata for columns missing from symbols.csv
+const (
+	defaultAssetClass    = "crypto"
+	defaultQuoteCurrency = "USD"
+	defaultPrecision     = 2
+)
+
+// Symbol describes a listed asset
+type Symbol struct {
+	Symbol        string   `json:"symbol"`
+	Name          string   `json:"name"`
+	AssetClass    string   `json:"asset_class"`
+	QuoteCurrency string   `json:"quote_currency"`
+	Precision     int      `json:"precision"`
+	TickSize      float64  `json:"tick_size"`
+	Venues        []string `json:"venues,omitempty"` // empty means every configured exchange
+	Tier          string   `json:"tier,omitempty"`   // tier override; empty leaves it to adaptive tiering
+	Enabled       bool     `json:"enabled"`
+}
+
+// HasVenue reports whether the asset trades on the given venue
+func (s Symbol) HasVenue(venue string) bool {
+	if len(s.Venues) == 0 {
+		return true
+	}
+	for _, v := range s.Venues {
+		if strings.EqualFold(v, venue) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads and validates symbols.csv. Only the symbol column is required;
+// the optional columns are name, asset_class, quote_currency, precision,
+// tick_size, venues (separated by ";"), tier and enabled. Tier overrides are
+// checked against t when it is not nil.
+func Load(filename string, t *tiers.Config) ([]Symbol, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parse(file, t)
+}
+
+// parse reads symbols from CSV, collecting every validation error
+func parse(r io.Reader, t *tiers.Config) ([]Symbol, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("symbols file is empty")
+	}
+
+	// Map header names to column positions
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["symbol"]; !ok {
+		return nil, errors.New("symbols file has no symbol column")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	list := make([]Symbol, 0, len(records)-1)
+	seen := make(map[string]int, len(records)-1)
+	var problems []string
+	for n, record := range records[1:] { // Skip header
+		line := n + 2
+		s, err := parseSymbol(record, field, t)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		if first, dup := seen[s.Symbol]; dup {
+			problems = append(problems, fmt.Sprintf("line %d: duplicate symbol %q (first on line %d)", line, s.Symbol, first))
+			continue
+		}
+		seen[s.Symbol] = line
+		list = append(list, s)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid symbols file: %s", strings.Join(problems, "; "))
+	}
+	return list, nil
+}
+
+// parseSymbol builds a Symbol from a CSV row, filling in defaults
+func parseSymbol(record []string, field func([]string, string) string, t *tiers.Config) (Symbol, error) {
+	s := Symbol{
+		Symbol:        strings.ToLower(field(record, "symbol")),
+		Name:          field(record, "name"),
+		AssetClass:    strings.ToLower(field(record, "asset_class")),
+		QuoteCurrency: strings.ToUpper(field(record, "quote_currency")),
+		Precision:     defaultPrecision,
+		Tier:          field(record, "tier"),
+		Enabled:       true,
+	}
+	if s.Symbol == "" {
+		return s, errors.New("symbol is empty")
+	}
+	if s.Name == "" {
+		s.Name = strings.ToUpper(s.Symbol)
+	}
+	if s.AssetClass == "" {
+		s.AssetClass = defaultAssetClass
+	}
+	if s.QuoteCurrency == "" {
+		s.QuoteCurrency = defaultQuoteCurrency
+	}
+
+	if v := field(record, "precision"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 0 || p > 18 {
+			return s, fmt.Errorf("%s: precision must be an integer between 0 and 18", s.Symbol)
+		}
+		s.Precision = p
+	}
+
+	minTick := math.Pow10(-s.Precision)
+	s.TickSize = minTick
+	if v := field(record, "tick_size"); v != "" {
+		tick, err := strconv.ParseFloat(v, 64)
+		if err != nil || tick <= 0 {
+			return s, fmt.Errorf("%s: tick_size must be a positive number", s.Symbol)
+		}
+		// A tick finer than the precision could never be displayed
+		if tick < minTick*(1-1e-9) {
+			return s, fmt.Errorf("%s: tick_size %v is finer than precision %d", s.Symbol, tick, s.Precision)
+		}
+		s.TickSize = tick
+	}
+
+	if v := field(record, "venues"); v != "" {
+		for _, venue := range strings.Split(v, ";") {
+			if venue = strings.TrimSpace(venue); venue != "" {
+				s.Venues = append(s.Venues, venue)
+			}
+		}
+	}
+
+	if s.Tier != "" && t != nil {
+		if _, ok := t.Get(s.Tier); !ok {
+			return s, fmt.Errorf("%s: unknown tier %q", s.Symbol, s.Tier)
+		}
+	}
+
+	if v := field(record, "enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("%s: enabled must be true or false", s.Symbol)
+		}
+		s.Enabled = enabled
+	}
+	return s, nil
+}
@@ -0,0 +1,128 @@
+package symbols
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"real-time-price-aggregator/internal/tiers"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []Symbol
+	}{
+		{
+			name: "symbol column only",
+			csv:  "symbol\nBTC\n eth \n",
+			want: []Symbol{
+				{Symbol: "btc", Name: "BTC", AssetClass: "crypto", QuoteCurrency: "USD", Precision: 2, TickSize: 0.01, Enabled: true},
+				{Symbol: "eth", Name: "ETH", AssetClass: "crypto", QuoteCurrency: "USD", Precision: 2, TickSize: 0.01, Enabled: true},
+			},
+		},
+		{
+			name: "every column",
+			csv: "symbol,name,asset_class,quote_currency,precision,tick_size,venues,tier,enabled\n" +
+				"aapl,Apple,Equity,usd,4,0.01,nasdaq; nyse,hot,false\n",
+			want: []Symbol{
+				{Symbol: "aapl", Name: "Apple", AssetClass: "equity", QuoteCurrency: "USD", Precision: 4, TickSize: 0.01, Venues: []string{"nasdaq", "nyse"}, Tier: "hot", Enabled: false},
+			},
+		},
+		{
+			name: "columns in any order and case",
+			csv:  "Enabled, Precision ,SYMBOL\ntrue,0,doge\n",
+			want: []Symbol{
+				{Symbol: "doge", Name: "DOGE", AssetClass: "crypto", QuoteCurrency: "USD", Precision: 0, TickSize: 1, Enabled: true},
+			},
+		},
+		{
+			name: "short rows",
+			csv:  "symbol,name,precision\nbtc\n",
+			want: []Symbol{
+				{Symbol: "btc", Name: "BTC", AssetClass: "crypto", QuoteCurrency: "USD", Precision: 2, TickSize: 0.01, Enabled: true},
+			},
+		},
+		{
+			name: "header only",
+			csv:  "symbol\n",
+			want: []Symbol{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(strings.NewReader(tt.csv), tiers.Default())
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []string // substrings of the error
+	}{
+		{name: "empty file", csv: "", want: []string{"empty"}},
+		{name: "no symbol column", csv: "name\nBitcoin\n", want: []string{"no symbol column"}},
+		{name: "empty symbol", csv: "symbol,name\n,Bitcoin\n", want: []string{"line 2: symbol is empty"}},
+		{name: "duplicate", csv: "symbol\nbtc\neth\nBTC\n", want: []string{`line 4: duplicate symbol "btc" (first on line 2)`}},
+		{name: "bad precision", csv: "symbol,precision\nbtc,19\n", want: []string{"precision must be an integer"}},
+		{name: "negative tick", csv: "symbol,tick_size\nbtc,-1\n", want: []string{"tick_size must be a positive number"}},
+		{name: "tick finer than precision", csv: "symbol,precision,tick_size\nbtc,2,0.001\n", want: []string{"finer than precision 2"}},
+		{name: "unknown tier", csv: "symbol,tier\nbtc,lukewarm\n", want: []string{`unknown tier "lukewarm"`}},
+		{name: "bad enabled", csv: "symbol,enabled\nbtc,maybe\n", want: []string{"enabled must be true or false"}},
+		{
+			name: "every problem is reported",
+			csv:  "symbol,precision,enabled\nbtc,x,true\neth,2,maybe\nsol,2,true\n",
+			want: []string{"line 2: btc: precision", "line 3: eth: enabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(strings.NewReader(tt.csv), tiers.Default())
+			if err == nil {
+				t.Fatalf("parsed %+v, want an error", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithoutTiers(t *testing.T) {
+	// Tier overrides can't be checked without a tier config
+	got, err := parse(strings.NewReader("symbol,tier\nbtc,lukewarm\n"), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(got) != 1 || got[0].Tier != "lukewarm" {
+		t.Errorf("parse = %+v, want btc with tier lukewarm", got)
+	}
+}
+
+func TestHasVenue(t *testing.T) {
+	tests := []struct {
+		venues []string
+		venue  string
+		want   bool
+	}{
+		{venues: nil, venue: "binance", want: true},
+		{venues: []string{"Binance", "kraken"}, venue: "binance", want: true},
+		{venues: []string{"kraken"}, venue: "binance", want: false},
+	}
+	for _, tt := range tests {
+		if got := (Symbol{Venues: tt.venues}).HasVenue(tt.venue); got != tt.want {
+			t.Errorf("%v.HasVenue(%q) = %v, want %v", tt.venues, tt.venue, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// internal/tiers/tiers.go
+package tiers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"real-time-price-aggregator/internal/ratelimiter"
+)
+
+// Duration wraps time.Duration so it can be written as "5s" in JSON
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration string such as "30s" or "5m"
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Tier describes how often assets in a tier are refreshed and how long their data is trusted
+type Tier struct {
+	Name            string   `json:"name"`
+	RefreshInterval Duration `json:"refresh_interval"`
+	CacheTTL        Duration `json:"cache_ttl"` // lower bound; the cache derives TTLs from RefreshInterval
+	// MaxDataAge is the age after which a read forces a refresh (0 disables the check)
+	MaxDataAge Duration `json:"max_data_age"`
+	// Size is the number of assets in the tier (0 on the last tier means "the rest")
+	Size int `json:"size"`
+	// Priority is "high", "normal" or "low"; it defaults from the tier's position
+	Priority string `json:"priority,omitempty"`
+}
+
+// BudgetPriority returns the exchange budget priority for the tier
+func (t Tier) BudgetPriority() ratelimiter.Priority {
+	switch t.Priority {
+	case "high":
+		return ratelimiter.High
+	case "normal":
+		return ratelimiter.Normal
+	default:
+		return ratelimiter.Low
+	}
+}
+
+// Config holds the ordered tier definitions, hottest first
+type Config struct {
+	Tiers []Tier `json:"tiers"`
+	index map[string]int
+}
+
+// Default returns the built-in hot/medium/cold tiers
+// Top 20 assets are hot, next 180 are medium, the rest are cold; only cold
+// data is checked for age, hot and medium rely on their short TTLs
+func Default() *Config {
+	c := &Config{
+		Tiers: []Tier{
+			{
+				Name:            "hot",
+				RefreshInterval: Duration{5 * time.Second},
+				CacheTTL:        Duration{10 * time.Second},
+				Size:            20,
+			},
+			{
+				Name:            "medium",
+				RefreshInterval: Duration{30 * time.Second},
+				CacheTTL:        Duration{1 * time.Minute},
+				Size:            180,
+			},
+			{
+				Name:            "cold",
+				RefreshInterval: Duration{5 * time.Minute},
+				CacheTTL:        Duration{5 * time.Minute},
+				MaxDataAge:      Duration{5 * time.Minute},
+			},
+		},
+	}
+	if err := c.validate(); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Load reads tier definitions from a JSON file such as:
+//
+//	{"tiers": [
+//	  {"name": "ultra-hot", "refresh_interval": "1s", "cache_ttl": "3s", "size": 5},
+//	  {"name": "hot", "refresh_interval": "5s", "cache_ttl": "10s", "size": 15},
+//	  {"name": "cold", "refresh_interval": "5m", "cache_ttl": "5m", "max_data_age": "5m"}
+//	]}
+func Load(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid tier config %s: %w", filename, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid tier config %s: %w", filename, err)
+	}
+	return &c, nil
+}
+
+// validate checks the tier definitions and fills in defaults
+func (c *Config) validate() error {
+	if len(c.Tiers) == 0 {
+		return errors.New("at least one tier is required")
+	}
+
+	c.index = make(map[string]int, len(c.Tiers))
+	last := len(c.Tiers) - 1
+	for i := range c.Tiers {
+		t := &c.Tiers[i]
+		if t.Name == "" {
+			return fmt.Errorf("tier %d has no name", i)
+		}
+		if _, dup := c.index[t.Name]; dup {
+			return fmt.Errorf("duplicate tier name %q", t.Name)
+		}
+		if t.RefreshInterval.Duration <= 0 {
+			return fmt.Errorf("tier %q needs a positive refresh_interval", t.Name)
+		}
+		if t.CacheTTL.Duration <= 0 {
+			t.CacheTTL = t.RefreshInterval
+		}
+		if t.Size < 0 || (t.Size == 0 && i != last) {
+			return fmt.Errorf("tier %q needs a positive size", t.Name)
+		}
+
+		switch t.Priority {
+		case "high", "normal", "low":
+		case "":
+			// Hottest tier goes first, coldest tier backs off first
+			switch {
+			case i == 0:
+				t.Priority = "high"
+			case i == last:
+				t.Priority = "low"
+			default:
+				t.Priority = "normal"
+			}
+		default:
+			return fmt.Errorf("tier %q has unknown priority %q", t.Name, t.Priority)
+		}
+
+		c.index[t.Name] = i
+	}
+	return nil
+}
+
+// Get returns the tier with the given name
+func (c *Config) Get(name string) (Tier, bool) {
+	i, ok := c.index[name]
+	if !ok {
+		return Tier{}, false
+	}
+	return c.Tiers[i], true
+}
+
+// Lookup returns the tier with the given name, or the coldest tier if unknown
+func (c *Config) Lookup(name string) Tier {
+	if t, ok := c.Get(name); ok {
+		return t
+	}
+	return c.Coldest()
+}
+
+// Hottest returns the first (most frequently refreshed) tier
+func (c *Config) Hottest() Tier {
+	return c.Tiers[0]
+}
+
+// Coldest returns the last tier, which holds every asset not ranked higher
+func (c *Config) Coldest() Tier {
+	return c.Tiers[len(c.Tiers)-1]
+}
+
+// ForRank returns the tier for an asset at the given popularity rank
+func (c *Config) ForRank(rank int) Tier {
+	limit := 0
+	for _, t := range c.Tiers {
+		limit += t.Size
+		if rank < limit {
+			return t
+		}
+	}
+	return c.Coldest()
+}
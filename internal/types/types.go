@@ -19,6 +19,9 @@ type PriceDataResponse struct {
 	LastUpdated string  `json:"last_updated"`
 	TimeAgo     string  `json:"time_ago"`               // New field for human-readable time
 	RefreshTier string  `json:"refresh_tier,omitempty"` // Optional field to show the refresh tier
+	Health      string  `json:"health,omitempty"`       // Set when refreshes for the asset are failing
+	Stale       bool    `json:"stale,omitempty"`        // Set when the price is older than its tier allows
+	Timestamp   int64   `json:"-"`                      // Unix seconds of LastUpdated, for the gRPC API
 }
 
 // FormatTimestamp converts a Unix timestamp to "YYYY-MM-DD HH:MM:SS" format in local time
@@ -59,6 +62,7 @@ func (p *PriceData) ToResponse() PriceDataResponse {
 		Price:       p.Price,
 		LastUpdated: FormatTimestamp(p.Timestamp),
 		TimeAgo:     FormatTimeAgo(p.Timestamp),
+		Timestamp:   p.Timestamp,
 	}
 }
 
@@ -9,12 +9,22 @@ curl -s http://localhost:8082/mock/ticker/asset1
 curl -s http://localhost:8083/mock/ticker/asset1
 
 # Refresh prices for asset1 and asset10001
-curl -X POST http://localhost:8080/refresh/asset1
-curl -X POST http://localhost:8080/refresh/asset10001
+curl -X POST http://localhost:8080/v1/refresh/asset1
+curl -X POST http://localhost:8080/v1/refresh/asset10001
 
 # Get prices for asset1 and asset10001
-curl -s http://localhost:8080/prices/asset1
-curl -s http://localhost:8080/prices/asset10001
+curl -s http://localhost:8080/v1/prices/asset1
+curl -s http://localhost:8080/v1/prices/asset10001
+
+# Stream live prices for asset1 for a few refreshes of the mock exchanges
+timeout 12 curl -sN "http://localhost:8080/v1/stream/prices?assets=asset1"
+
+# Subscribe to asset1 over WebSocket (needs websocat): expect "subscribed",
+# a snapshot, then updates at most every 250ms. The ordering, throttling and
+# unsubscribing are asserted by go test ./internal/api
+if command -v websocat >/dev/null; then
+  echo '{"action": "subscribe", "assets": ["asset1"], "throttle_ms": 250}' | timeout 12 websocat -n ws://localhost:8080/v1/ws/prices
+fi
 
 # Scan DynamoDB for asset1
 # aws dynamodb scan --table-name prices --region us-west-2 --query "Items[?asset.S=='asset1']" --output json